@@ -2,15 +2,12 @@ package main
 
 import (
 	"context"
-	"encoding/csv"
-	"io"
-	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/ernur-eskermes/product-store/pkg/httpclient"
 	"github.com/ernur-eskermes/product-store/pkg/logger"
-	"github.com/gocarina/gocsv"
 
 	"github.com/ernur-eskermes/product-store/internal/transport/grpc"
 	grpcHandler "github.com/ernur-eskermes/product-store/internal/transport/grpc/handlers"
@@ -22,15 +19,6 @@ import (
 	"github.com/ernur-eskermes/product-store/internal/storage"
 )
 
-func init() {
-	gocsv.SetCSVReader(func(in io.Reader) gocsv.CSVReader {
-		r := csv.NewReader(in)
-		r.Comma = ';'
-
-		return r
-	})
-}
-
 func main() {
 	log := logger.New("debug", "product_store")
 	defer logger.Cleanup(log)
@@ -47,14 +35,62 @@ func main() {
 
 	db := mongoClient.Database(cfg.Mongo.Database)
 
-	storages := storage.New(db)
+	httpClient := httpclient.New(httpclient.Policy{
+		AllowedSchemes:       cfg.Download.AllowedSchemes,
+		AllowedHosts:         cfg.Download.AllowedHosts,
+		DeniedHosts:          cfg.Download.DeniedHosts,
+		AllowPrivateNetworks: cfg.Download.AllowPrivateNetworks,
+		MaxRedirects:         cfg.Download.MaxRedirects,
+		MaxBodySize:          cfg.Download.MaxBodySize,
+	})
+
+	storages := storage.New(db, storage.Collation{
+		Locale:        cfg.List.CollationLocale,
+		CaseSensitive: cfg.List.CollationCaseSensitive,
+	})
+	if err = storages.EnsureIndexes(context.Background()); err != nil {
+		log.Fatal("error when creating mongodb indexes", logger.Error(err))
+	}
+
+	if err = storages.MigratePrices(context.Background(), cfg.Fetch.DefaultCurrency); err != nil {
+		log.Fatal("error when migrating prices", logger.Error(err))
+	}
+
+	if err = storages.MigrateNameKeys(context.Background()); err != nil {
+		log.Fatal("error when migrating product name keys", logger.Error(err))
+	}
+
+	if err = storages.MigrateChangeTracking(context.Background()); err != nil {
+		log.Fatal("error when migrating product change tracking", logger.Error(err))
+	}
+
 	services := service.New(service.Deps{
-		ProductStorage: storages.Product,
-		HTTPClient:     http.DefaultClient,
+		Logger:              log,
+		ProductStorage:      storages.Product,
+		PriceHistoryStorage: storages.PriceHistory,
+		FetchJobStorage:     storages.FetchJob,
+		FeedSourceStorage:   storages.FeedSource,
+		FeedCacheStorage:    storages.FeedCache,
+		HTTPClient:          httpClient,
+		FetchConfig:         cfg.Fetch,
+		JobsConfig:          cfg.Jobs,
+		SchedulerConfig:     cfg.Scheduler,
+		DropConfig:          cfg.Drop,
+		ListConfig:          cfg.List,
 	})
 
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	services.FetchJob.Start(jobsCtx)
+	services.FeedSource.Start(jobsCtx)
+
+	if err = services.DropDir.Start(jobsCtx); err != nil {
+		log.Fatal("error when starting the drop directory watcher", logger.Error(err))
+	}
+
 	grpcHandlers := grpcHandler.New(grpcHandler.Deps{
-		ProductService: services.Product,
+		ProductService:    services.Product,
+		FetchJobService:   services.FetchJob,
+		FeedSourceService: services.FeedSource,
 	})
 	grpcSrv := grpc.New(grpc.Deps{
 		Logger:         log,
@@ -78,6 +114,11 @@ func main() {
 
 	grpcSrv.Stop()
 
+	stopJobs()
+	services.FeedSource.Wait()
+	services.DropDir.Wait()
+	services.FetchJob.Wait()
+
 	if err = mongoClient.Disconnect(context.Background()); err != nil {
 		log.Error("disconnect mongodb error", logger.Error(err))
 	}
@@ -3,14 +3,15 @@ module github.com/ernur-eskermes/product-store
 go 1.18
 
 require (
-	github.com/gocarina/gocsv v0.0.0-20220520193141-bb9bebb918c3
 	github.com/golang/mock v1.6.0
 	github.com/golang/protobuf v1.5.2
 	github.com/grpc-ecosystem/go-grpc-middleware v1.3.0
 	github.com/kelseyhightower/envconfig v1.4.0
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/stretchr/testify v1.7.0
 	go.mongodb.org/mongo-driver v1.10.0
 	go.uber.org/zap v1.10.0
+	golang.org/x/text v0.3.7
 	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013
 	google.golang.org/grpc v1.46.2
 	google.golang.org/protobuf v1.28.0
@@ -33,6 +34,5 @@ require (
 	golang.org/x/net v0.0.0-20211112202133-69e39bad7dc2 // indirect
 	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c // indirect
 	golang.org/x/sys v0.0.0-20210806184541-e5e7981a1069 // indirect
-	golang.org/x/text v0.3.7 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
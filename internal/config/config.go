@@ -1,6 +1,11 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/kelseyhightower/envconfig"
 )
 
@@ -15,9 +20,95 @@ type GRPCConfig struct {
 	Port int `required:"true"`
 }
 
+type FetchConfig struct {
+	BatchSize       int           `default:"1000" split_words:"true"`
+	MaxRejectedRows int           `default:"1000" split_words:"true"`
+	DiffSampleSize  int           `default:"20" split_words:"true"`
+	MaxRetries      int           `default:"3" split_words:"true"`
+	RetryBaseDelay  time.Duration `default:"500ms" split_words:"true"`
+	RetryMaxDelay   time.Duration `default:"30s" split_words:"true"`
+	// DownloadTimeout bounds a whole download, retries included.
+	DownloadTimeout time.Duration `default:"5m" split_words:"true"`
+	// DefaultCurrency is the ISO 4217 code of prices in feeds that do not give one.
+	DefaultCurrency string `default:"USD" split_words:"true"`
+	// MaxTrackedKeys bounds the product keys remembered while a feed is read to find
+	// duplicates. Duplicates of products past it go unchecked.
+	MaxTrackedKeys int `default:"100000" split_words:"true"`
+	// FileRoot is the directory file URLs may point into. They are refused when it is
+	// empty.
+	FileRoot string `split_words:"true"`
+	// SecretsFile is a JSON file mapping secret names to FeedSecrets, loaded into
+	// Secrets.
+	SecretsFile string                `split_words:"true"`
+	Secrets     map[string]FeedSecret `ignored:"true"`
+}
+
+// FeedSecret is a set of feed credentials that fetches can refer to by name, so that
+// clients do not need to know them.
+type FeedSecret struct {
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	BearerToken string `json:"bearer_token"`
+	HeaderName  string `json:"header_name"`
+	HeaderValue string `json:"header_value"`
+}
+
+type JobsConfig struct {
+	Workers           int           `default:"4"`
+	PollInterval      time.Duration `default:"5s" split_words:"true"`
+	HeartbeatInterval time.Duration `default:"2s" split_words:"true"`
+	StaleAfter        time.Duration `default:"1m" split_words:"true"`
+}
+
+// DownloadConfig restricts where feeds may be downloaded from and how much may be
+// downloaded. Host entries starting with a dot match every subdomain.
+type DownloadConfig struct {
+	AllowedSchemes       []string `default:"http,https" split_words:"true"`
+	AllowedHosts         []string `split_words:"true"`
+	DeniedHosts          []string `split_words:"true"`
+	AllowPrivateNetworks bool     `split_words:"true"`
+	MaxRedirects         int      `default:"5" split_words:"true"`
+	MaxBodySize          int64    `default:"104857600" split_words:"true"`
+}
+
+// DropConfig sets up the drop directory, whose files are ingested and then moved to
+// ArchiveDir, or FailedDir when they could not be ingested. Files are moved to
+// ProcessingDir while they are ingested, which must be on the same file system as Dir
+// and not shared with other instances. All three default to subdirectories of Dir,
+// ProcessingDir to one named after the host. No directory is watched when Dir is
+// empty.
+type DropConfig struct {
+	Dir           string
+	ArchiveDir    string        `split_words:"true"`
+	FailedDir     string        `split_words:"true"`
+	ProcessingDir string        `split_words:"true"`
+	Interval      time.Duration `default:"10s"`
+	SettleTime    time.Duration `default:"5s" split_words:"true"`
+}
+
+// ListConfig sets up product listing. PageTokenKey signs page tokens, and must be the
+// same for every instance serving the API, so that they accept each other's tokens
+// and tokens outlive restarts. Names and SKUs sort by the rules of CollationLocale,
+// an ICU locale, ignoring case unless CollationCaseSensitive is set.
+type ListConfig struct {
+	PageTokenKey           string `required:"true" split_words:"true"`
+	CollationLocale        string `default:"en" split_words:"true"`
+	CollationCaseSensitive bool   `split_words:"true"`
+}
+
+type SchedulerConfig struct {
+	Interval time.Duration `default:"15s"`
+}
+
 type Config struct {
-	Mongo MongoConfig `required:"true"`
-	GRPC  GRPCConfig  `required:"true"`
+	Mongo     MongoConfig `required:"true"`
+	GRPC      GRPCConfig  `required:"true"`
+	Fetch     FetchConfig
+	Jobs      JobsConfig
+	Scheduler SchedulerConfig
+	Download  DownloadConfig
+	Drop      DropConfig
+	List      ListConfig
 }
 
 func New() (*Config, error) {
@@ -31,5 +122,54 @@ func New() (*Config, error) {
 		return nil, err
 	}
 
+	if err := envconfig.Process("fetch", &cfg.Fetch); err != nil {
+		return nil, err
+	}
+
+	if cfg.Fetch.SecretsFile != "" {
+		secrets, err := loadSecrets(cfg.Fetch.SecretsFile)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.Fetch.Secrets = secrets
+	}
+
+	if err := envconfig.Process("jobs", &cfg.Jobs); err != nil {
+		return nil, err
+	}
+
+	if err := envconfig.Process("scheduler", &cfg.Scheduler); err != nil {
+		return nil, err
+	}
+
+	if err := envconfig.Process("download", &cfg.Download); err != nil {
+		return nil, err
+	}
+
+	if err := envconfig.Process("drop", &cfg.Drop); err != nil {
+		return nil, err
+	}
+
+	if err := envconfig.Process("list", &cfg.List); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
+
+func loadSecrets(path string) (map[string]FeedSecret, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var secrets map[string]FeedSecret
+
+	// The file content is left out of the error, as it holds the secrets.
+	if err = json.Unmarshal(b, &secrets); err != nil {
+		return nil, fmt.Errorf("parse feed secrets file %s: invalid JSON", path)
+	}
+
+	return secrets, nil
+}
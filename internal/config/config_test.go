@@ -1,9 +1,10 @@
 package config_test
 
 import (
-	"log"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/ernur-eskermes/product-store/internal/config"
 	"github.com/stretchr/testify/require"
@@ -15,33 +16,29 @@ type env struct {
 	mongoPassword string
 	mongoDatabase string
 	grpcPort      string
+	jobsWorkers   string
+	deniedHosts   string
+	pageTokenKey  string
 }
 
-func setEnv(env env) {
-	var err error
-	if env.mongoURI != "" {
-		if err = os.Setenv("MONGO_URI", env.mongoURI); err != nil {
-			log.Fatal(err)
-		}
-	}
-	if env.mongoUser != "" {
-		if err = os.Setenv("MONGO_USER", env.mongoUser); err != nil {
-			log.Fatal(err)
-		}
-	}
-	if env.mongoPassword != "" {
-		if err = os.Setenv("MONGO_PASSWORD", env.mongoPassword); err != nil {
-			log.Fatal(err)
-		}
-	}
-	if env.mongoDatabase != "" {
-		if err = os.Setenv("MONGO_DATABASE", env.mongoDatabase); err != nil {
-			log.Fatal(err)
-		}
-	}
-	if env.grpcPort != "" {
-		if err = os.Setenv("GRPC_PORT", env.grpcPort); err != nil {
-			log.Fatal(err)
+// setEnv sets the variables of env for the rest of t, and unsets those env leaves
+// empty, so that no case sees the variables of another.
+func setEnv(t *testing.T, env env) {
+	for key, value := range map[string]string{
+		"MONGO_URI":             env.mongoURI,
+		"MONGO_USER":            env.mongoUser,
+		"MONGO_PASSWORD":        env.mongoPassword,
+		"MONGO_DATABASE":        env.mongoDatabase,
+		"GRPC_PORT":             env.grpcPort,
+		"JOBS_WORKERS":          env.jobsWorkers,
+		"DOWNLOAD_DENIED_HOSTS": env.deniedHosts,
+		"LIST_PAGE_TOKEN_KEY":   env.pageTokenKey,
+	} {
+		// t.Setenv restores the variable once t is done, also when it is unset here.
+		t.Setenv(key, value)
+
+		if value == "" {
+			require.NoError(t, os.Unsetenv(key))
 		}
 	}
 }
@@ -62,6 +59,7 @@ func TestNew(t *testing.T) {
 				mongoPassword: "test_password",
 				mongoUser:     "test_user",
 				mongoURI:      "test_uri",
+				pageTokenKey:  "test_key",
 			},
 			want: &config.Config{
 				GRPC: config.GRPCConfig{
@@ -73,6 +71,39 @@ func TestNew(t *testing.T) {
 					User:     "test_user",
 					Password: "test_password",
 				},
+				Fetch: config.FetchConfig{
+					BatchSize:       1000,
+					MaxRejectedRows: 1000,
+					DiffSampleSize:  20,
+					MaxTrackedKeys:  100000,
+					MaxRetries:      3,
+					RetryBaseDelay:  500 * time.Millisecond,
+					RetryMaxDelay:   30 * time.Second,
+					DownloadTimeout: 5 * time.Minute,
+					DefaultCurrency: "USD",
+				},
+				Jobs: config.JobsConfig{
+					Workers:           4,
+					PollInterval:      5 * time.Second,
+					HeartbeatInterval: 2 * time.Second,
+					StaleAfter:        time.Minute,
+				},
+				Scheduler: config.SchedulerConfig{
+					Interval: 15 * time.Second,
+				},
+				Download: config.DownloadConfig{
+					AllowedSchemes: []string{"http", "https"},
+					MaxRedirects:   5,
+					MaxBodySize:    100 << 20,
+				},
+				Drop: config.DropConfig{
+					Interval:   10 * time.Second,
+					SettleTime: 5 * time.Second,
+				},
+				List: config.ListConfig{
+					PageTokenKey:    "test_key",
+					CollationLocale: "en",
+				},
 			},
 		},
 		{
@@ -83,6 +114,7 @@ func TestNew(t *testing.T) {
 				mongoPassword: "test_password",
 				mongoUser:     "test_user",
 				mongoURI:      "test_uri",
+				pageTokenKey:  "test_key",
 			},
 			expErr: "envconfig.Process: assigning GRPC_PORT to Port: converting 'asd' to type int. details: strconv.ParseInt: parsing \"asd\": invalid syntax",
 		},
@@ -96,11 +128,136 @@ func TestNew(t *testing.T) {
 			},
 			expErr: "required key MONGO_URI missing value",
 		},
+		{
+			name: "empty_page_token_key",
+			env: env{
+				grpcPort:      "9000",
+				mongoDatabase: "test_database",
+				mongoPassword: "test_password",
+				mongoUser:     "test_user",
+				mongoURI:      "test_uri",
+			},
+			expErr: "required key LIST_PAGE_TOKEN_KEY missing value",
+		},
+		{
+			name: "custom_jobs_workers",
+			env: env{
+				grpcPort:      "9000",
+				mongoDatabase: "test_database",
+				mongoPassword: "test_password",
+				mongoUser:     "test_user",
+				mongoURI:      "test_uri",
+				pageTokenKey:  "test_key",
+				jobsWorkers:   "8",
+			},
+			want: &config.Config{
+				GRPC: config.GRPCConfig{
+					Port: 9000,
+				},
+				Mongo: config.MongoConfig{
+					URI:      "test_uri",
+					Database: "test_database",
+					User:     "test_user",
+					Password: "test_password",
+				},
+				Fetch: config.FetchConfig{
+					BatchSize:       1000,
+					MaxRejectedRows: 1000,
+					DiffSampleSize:  20,
+					MaxTrackedKeys:  100000,
+					MaxRetries:      3,
+					RetryBaseDelay:  500 * time.Millisecond,
+					RetryMaxDelay:   30 * time.Second,
+					DownloadTimeout: 5 * time.Minute,
+					DefaultCurrency: "USD",
+				},
+				Jobs: config.JobsConfig{
+					Workers:           8,
+					PollInterval:      5 * time.Second,
+					HeartbeatInterval: 2 * time.Second,
+					StaleAfter:        time.Minute,
+				},
+				Scheduler: config.SchedulerConfig{
+					Interval: 15 * time.Second,
+				},
+				Download: config.DownloadConfig{
+					AllowedSchemes: []string{"http", "https"},
+					MaxRedirects:   5,
+					MaxBodySize:    100 << 20,
+				},
+				Drop: config.DropConfig{
+					Interval:   10 * time.Second,
+					SettleTime: 5 * time.Second,
+				},
+				List: config.ListConfig{
+					PageTokenKey:    "test_key",
+					CollationLocale: "en",
+				},
+			},
+		},
+		{
+			name: "custom_download_policy",
+			env: env{
+				grpcPort:      "9000",
+				mongoDatabase: "test_database",
+				mongoPassword: "test_password",
+				mongoUser:     "test_user",
+				mongoURI:      "test_uri",
+				pageTokenKey:  "test_key",
+				jobsWorkers:   "8",
+				deniedHosts:   "mongo,.internal",
+			},
+			want: &config.Config{
+				GRPC: config.GRPCConfig{
+					Port: 9000,
+				},
+				Mongo: config.MongoConfig{
+					URI:      "test_uri",
+					Database: "test_database",
+					User:     "test_user",
+					Password: "test_password",
+				},
+				Fetch: config.FetchConfig{
+					BatchSize:       1000,
+					MaxRejectedRows: 1000,
+					DiffSampleSize:  20,
+					MaxTrackedKeys:  100000,
+					MaxRetries:      3,
+					RetryBaseDelay:  500 * time.Millisecond,
+					RetryMaxDelay:   30 * time.Second,
+					DownloadTimeout: 5 * time.Minute,
+					DefaultCurrency: "USD",
+				},
+				Jobs: config.JobsConfig{
+					Workers:           8,
+					PollInterval:      5 * time.Second,
+					HeartbeatInterval: 2 * time.Second,
+					StaleAfter:        time.Minute,
+				},
+				Scheduler: config.SchedulerConfig{
+					Interval: 15 * time.Second,
+				},
+				Download: config.DownloadConfig{
+					AllowedSchemes: []string{"http", "https"},
+					DeniedHosts:    []string{"mongo", ".internal"},
+					MaxRedirects:   5,
+					MaxBodySize:    100 << 20,
+				},
+				Drop: config.DropConfig{
+					Interval:   10 * time.Second,
+					SettleTime: 5 * time.Second,
+				},
+				List: config.ListConfig{
+					PageTokenKey:    "test_key",
+					CollationLocale: "en",
+				},
+			},
+		},
 	}
 
 	for _, s := range cases {
 		t.Run(s.name, func(t *testing.T) {
-			setEnv(s.env)
+			setEnv(t, s.env)
 
 			cfg, err := config.New()
 			if err != nil {
@@ -111,3 +268,41 @@ func TestNew(t *testing.T) {
 		})
 	}
 }
+
+func TestNew_SecretsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"supplier": {"username": "user", "password": "pass"}}`), 0o600))
+
+	t.Setenv("MONGO_URI", "test_uri")
+	t.Setenv("MONGO_USER", "test_user")
+	t.Setenv("MONGO_PASSWORD", "test_password")
+	t.Setenv("MONGO_DATABASE", "test_database")
+	t.Setenv("GRPC_PORT", "9000")
+	t.Setenv("LIST_PAGE_TOKEN_KEY", "test_key")
+	t.Setenv("FETCH_SECRETS_FILE", path)
+
+	cfg, err := config.New()
+	require.NoError(t, err)
+	require.Equal(t, map[string]config.FeedSecret{"supplier": {Username: "user", Password: "pass"}}, cfg.Fetch.Secrets)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"supplier": "pass"}`), 0o600))
+
+	_, err = config.New()
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "pass")
+}
+
+func TestNew_Collation(t *testing.T) {
+	t.Setenv("MONGO_URI", "test_uri")
+	t.Setenv("MONGO_USER", "test_user")
+	t.Setenv("MONGO_PASSWORD", "test_password")
+	t.Setenv("MONGO_DATABASE", "test_database")
+	t.Setenv("GRPC_PORT", "9000")
+	t.Setenv("LIST_PAGE_TOKEN_KEY", "test_key")
+	t.Setenv("LIST_COLLATION_LOCALE", "de")
+	t.Setenv("LIST_COLLATION_CASE_SENSITIVE", "true")
+
+	cfg, err := config.New()
+	require.NoError(t, err)
+	require.Equal(t, config.ListConfig{PageTokenKey: "test_key", CollationLocale: "de", CollationCaseSensitive: true}, cfg.List)
+}
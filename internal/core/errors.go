@@ -0,0 +1,65 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrUnsupportedScheme = errors.New("unsupported feed URL scheme")
+	ErrFeedOutsideRoot   = errors.New("feed file is outside the feed root")
+	ErrUnknownSecret     = errors.New("unknown feed secret")
+)
+
+// FeedError reports a feed that could not be parsed, as opposed to a failure to
+// store the products read from it.
+type FeedError struct {
+	Err error
+}
+
+func (e *FeedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FeedError) Unwrap() error {
+	return e.Err
+}
+
+// DownloadError reports a feed server that could not be reached, or a download that
+// broke off.
+type DownloadError struct {
+	Err error
+}
+
+func (e *DownloadError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DownloadError) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatusError reports a feed request answered with a status other than 2xx or
+// 304. RetryAfter is the delay the server asked for, if any.
+type HTTPStatusError struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("feed server responded %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Temporary tells whether the request may succeed if it is made again.
+func (e *HTTPStatusError) Temporary() bool {
+	switch {
+	case e.StatusCode == http.StatusRequestTimeout, e.StatusCode == http.StatusTooManyRequests:
+		return true
+	case e.StatusCode == http.StatusNotImplemented:
+		return false
+	default:
+		return e.StatusCode >= 500
+	}
+}
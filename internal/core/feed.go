@@ -0,0 +1,212 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	ProductFieldName     = "name"
+	ProductFieldPrice    = "price"
+	ProductFieldSKU      = "sku"
+	ProductFieldCurrency = "currency"
+)
+
+type FeedFormat string
+
+const (
+	FeedFormatCSV    FeedFormat = "csv"
+	FeedFormatJSON   FeedFormat = "json"
+	FeedFormatNDJSON FeedFormat = "ndjson"
+	FeedFormatXML    FeedFormat = "xml"
+)
+
+var FeedFormats = []FeedFormat{FeedFormatCSV, FeedFormatJSON, FeedFormatNDJSON, FeedFormatXML}
+
+var ProductFields = []string{ProductFieldName, ProductFieldPrice, ProductFieldSKU, ProductFieldCurrency}
+
+var (
+	DefaultCSVDelimiter = ";"
+	DefaultCSVQuote     = `"`
+	// DefaultCSVColumns maps header names to product fields for feeds with a header.
+	DefaultCSVColumns = map[string]string{"PRODUCT NAME": ProductFieldName, "PRICE": ProductFieldPrice}
+	// DefaultHeaderlessCSVColumns maps 1-based column positions to product fields for
+	// feeds without a header.
+	DefaultHeaderlessCSVColumns = map[string]string{"1": ProductFieldName, "2": ProductFieldPrice}
+	// OptionalCSVColumns maps header names to product fields that are read along with
+	// DefaultCSVColumns when the header has them.
+	OptionalCSVColumns = map[string]string{"SKU": ProductFieldSKU, "CURRENCY": ProductFieldCurrency}
+)
+
+// CSVDialect describes the layout of a CSV feed. Empty values fall back to the
+// defaults above.
+type CSVDialect struct {
+	Delimiter string `bson:"delimiter,omitempty"`
+	Quote     string `bson:"quote,omitempty"`
+	NoHeader  bool   `bson:"no_header,omitempty"`
+	// Columns maps a header name, or a 1-based column position when NoHeader is set,
+	// to one of ProductFields.
+	Columns map[string]string `bson:"columns,omitempty"`
+}
+
+// FetchOptions describes how a feed should be read. An empty Format is detected from
+// the response Content-Type or the file extension of the feed URL.
+type FetchOptions struct {
+	Format FeedFormat `bson:"format,omitempty"`
+	CSV    CSVDialect `bson:"csv"`
+	// Currency is the currency of rows that do not give one, or the default if empty.
+	Currency string `bson:"currency,omitempty"`
+	// DryRun compares the feed with the catalog instead of storing it.
+	DryRun bool `bson:"dry_run,omitempty"`
+	// Snapshot discontinues the products of the source that the feed no longer has.
+	Snapshot bool      `bson:"snapshot,omitempty"`
+	Auth     *FeedAuth `bson:"auth,omitempty"`
+}
+
+// FeedAuth holds the credentials sent with the requests for a feed: a user name and
+// password for basic auth, a bearer token, or an API key sent in the header HeaderName.
+// Secret names credentials from the server config instead.
+type FeedAuth struct {
+	Secret      string `bson:"secret,omitempty"`
+	Username    string `bson:"username,omitempty"`
+	Password    string `bson:"password,omitempty"`
+	BearerToken string `bson:"bearer_token,omitempty"`
+	HeaderName  string `bson:"header_name,omitempty"`
+	HeaderValue string `bson:"header_value,omitempty"`
+}
+
+// RedactedBearerToken stands in for the bearer token of redacted credentials.
+const RedactedBearerToken = "xxxxx"
+
+// ErrFeedAuthNotRestored is returned by Unredact for credentials without a secret value
+// that cannot be taken from the stored ones.
+var ErrFeedAuthNotRestored = errors.New("secret values must be sent again")
+
+// Redacted returns a copy of a with the password, token and header value left out. A
+// bearer token is replaced by RedactedBearerToken.
+func (a FeedAuth) Redacted() FeedAuth {
+	r := FeedAuth{Secret: a.Secret, Username: a.Username, HeaderName: a.HeaderName}
+	if a.BearerToken != "" {
+		r.BearerToken = RedactedBearerToken
+	}
+
+	return r
+}
+
+// Unredact returns a with the secret value of stored restored when a is redacted, for
+// the same user, header or bearer token only. It returns ErrFeedAuthNotRestored when a
+// holds no secret value and stored has none of the same kind.
+func (a FeedAuth) Unredact(stored *FeedAuth) (FeedAuth, error) {
+	if a.Secret != "" || a.Password != "" || a.HeaderValue != "" || a.BearerToken != "" && a.BearerToken != RedactedBearerToken {
+		return a, nil
+	}
+
+	switch {
+	case stored == nil:
+		return FeedAuth{}, ErrFeedAuthNotRestored
+	case a.Username != "" && a.Username == stored.Username:
+		a.Password = stored.Password
+	case a.HeaderName != "" && a.HeaderName == stored.HeaderName:
+		a.HeaderValue = stored.HeaderValue
+	case a.BearerToken == RedactedBearerToken && stored.BearerToken != "":
+		a.BearerToken = stored.BearerToken
+	default:
+		return FeedAuth{}, ErrFeedAuthNotRestored
+	}
+
+	return a, nil
+}
+
+// String describes a without its secret values.
+func (a FeedAuth) String() string {
+	r := a.Redacted()
+
+	return fmt.Sprintf("{Secret:%s Username:%s HeaderName:%s}", r.Secret, r.Username, r.HeaderName)
+}
+
+const (
+	// MaxProductNameLength is the longest product name, in characters, a feed may contain.
+	MaxProductNameLength = 255
+	// MaxProductSKULength is the longest SKU, in characters, a feed may contain.
+	MaxProductSKULength = 64
+)
+
+// RowError describes a rejected feed row. Line is its line in the feed, or its 1-based
+// position in JSON feeds, and File the archive entry it was read from, if any.
+type RowError struct {
+	File   string `bson:"file,omitempty"`
+	Line   int    `bson:"line"`
+	Column string `bson:"column,omitempty"`
+	Reason string `bson:"reason"`
+}
+
+func (e *RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Reason)
+	}
+
+	return fmt.Sprintf("line %d, column %q: %s", e.Line, e.Column, e.Reason)
+}
+
+// FetchReport summarises a fetch. RejectedRows keeps at most the configured number of
+// rejected rows, and dry runs set Diff instead of the product counts.
+type FetchReport struct {
+	RowsRead             int           `bson:"rows_read"`
+	RowsAccepted         int           `bson:"rows_accepted"`
+	RowsRejected         int           `bson:"rows_rejected"`
+	RejectedRows         []RowError    `bson:"rejected_rows"`
+	ProductsInserted     int           `bson:"products_inserted"`
+	ProductsRepriced     int           `bson:"products_repriced"`
+	ProductsUnchanged    int           `bson:"products_unchanged"`
+	ProductsDiscontinued int           `bson:"products_discontinued"`
+	BytesDownloaded      int64         `bson:"bytes_downloaded"`
+	Duration             time.Duration `bson:"duration"`
+	Diff                 *CatalogDiff  `bson:"diff,omitempty"`
+	Skipped              FetchSkip     `bson:"skipped,omitempty"`
+	DiscontinueSkipped   bool          `bson:"discontinue_skipped,omitempty"`
+	DuplicatesUnchecked  bool          `bson:"duplicates_unchecked,omitempty"`
+}
+
+// FetchSkip tells why a fetch left the catalog alone.
+type FetchSkip string
+
+const (
+	// FetchSkipNotModified means the server answered 304 Not Modified.
+	FetchSkipNotModified FetchSkip = "not_modified"
+	// FetchSkipSameContent means the feed is byte-identical to the last one ingested.
+	FetchSkipSameContent FetchSkip = "same_content"
+)
+
+// FeedCache holds the validators and body hash of the last successful fetch of a URL
+// with Options.
+type FeedCache struct {
+	URL          string       `bson:"_id"`
+	Options      FetchOptions `bson:"options"`
+	ETag         string       `bson:"etag,omitempty"`
+	LastModified string       `bson:"last_modified,omitempty"`
+	ContentHash  string       `bson:"content_hash"`
+	FetchedAt    time.Time    `bson:"fetched_at"`
+}
+
+// ProductDiff is a product a dry run would touch, with its stored and feed prices.
+type ProductDiff struct {
+	SKU      string `bson:"sku,omitempty"`
+	Name     string `bson:"name"`
+	OldPrice Money  `bson:"old_price"`
+	NewPrice Money  `bson:"new_price"`
+}
+
+// DiffGroup counts the products of a group and keeps a sample of them.
+type DiffGroup struct {
+	Count   int           `bson:"count"`
+	Samples []ProductDiff `bson:"samples"`
+}
+
+// CatalogDiff describes what a fetch would do to the catalog.
+type CatalogDiff struct {
+	Created   DiffGroup `bson:"created"`
+	Repriced  DiffGroup `bson:"repriced"`
+	Unchanged DiffGroup `bson:"unchanged"`
+	Missing   DiffGroup `bson:"missing"`
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	FeedSourceDefaultSort  = "name"
+	FeedSourceSortSafeList = []string{
+		"name", "-name",
+		"created_at", "-created_at",
+		"next_run_at", "-next_run_at",
+	}
+)
+
+var ErrFeedSourceNotFound = errors.New("feed source not found")
+
+// FeedSource is a feed that is fetched on a schedule. Schedule is a standard five
+// field cron expression, or a descriptor such as "@hourly", evaluated in UTC unless it
+// starts with CRON_TZ=.
+type FeedSource struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Name      string             `bson:"name"`
+	URL       string             `bson:"url"`
+	Options   FetchOptions       `bson:"options"`
+	Schedule  string             `bson:"schedule"`
+	Enabled   bool               `bson:"enabled"`
+	NextRunAt time.Time          `bson:"next_run_at,omitempty"`
+	LastRunAt time.Time          `bson:"last_run_at,omitempty"`
+	LastJobID primitive.ObjectID `bson:"last_job_id,omitempty"`
+	CreatedAt time.Time          `bson:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at"`
+}
+
+// NextRun returns the first time after after that schedule fires.
+func NextRun(schedule string, after time.Time) (time.Time, error) {
+	s, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return s.Next(after.UTC()), nil
+}
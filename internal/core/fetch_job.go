@@ -0,0 +1,60 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type FetchJobStatus string
+
+const (
+	FetchJobPending   FetchJobStatus = "pending"
+	FetchJobRunning   FetchJobStatus = "running"
+	FetchJobSucceeded FetchJobStatus = "succeeded"
+	FetchJobFailed    FetchJobStatus = "failed"
+	FetchJobCancelled FetchJobStatus = "cancelled"
+)
+
+var (
+	FetchJobDefaultSort  = "-created_at"
+	FetchJobSortSafeList = []string{
+		"created_at", "-created_at",
+		"started_at", "-started_at",
+		"finished_at", "-finished_at",
+	}
+	FetchJobStatuses = []FetchJobStatus{
+		FetchJobPending, FetchJobRunning, FetchJobSucceeded, FetchJobFailed, FetchJobCancelled,
+	}
+)
+
+var (
+	ErrFetchJobNotFound = errors.New("fetch job not found")
+	ErrFetchJobFinished = errors.New("fetch job is already finished")
+)
+
+type FetchJob struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty"`
+	URL             string             `bson:"url"`
+	Options         FetchOptions       `bson:"options"`
+	Status          FetchJobStatus     `bson:"status"`
+	RowsProcessed   int                `bson:"rows_processed"`
+	Errors          []string           `bson:"errors"`
+	Report          FetchReport        `bson:"report"`
+	CancelRequested bool               `bson:"cancel_requested"`
+	WorkerID        string             `bson:"worker_id,omitempty"`
+	CreatedAt       time.Time          `bson:"created_at"`
+	StartedAt       time.Time          `bson:"started_at,omitempty"`
+	FinishedAt      time.Time          `bson:"finished_at,omitempty"`
+	HeartbeatAt     time.Time          `bson:"heartbeat_at,omitempty"`
+}
+
+func (j FetchJob) Finished() bool {
+	return j.Status == FetchJobSucceeded || j.Status == FetchJobFailed || j.Status == FetchJobCancelled
+}
+
+// FetchJobFilter narrows a fetch job listing. An empty Status matches every job.
+type FetchJobFilter struct {
+	Status FetchJobStatus
+}
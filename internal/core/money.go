@@ -0,0 +1,73 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ernur-eskermes/product-store/pkg/iso4217"
+)
+
+var ErrInvalidCurrency = errors.New("must be a three-letter ISO 4217 currency code")
+
+// Money is an amount in the minor units of Currency, an ISO 4217 code: 1299 USD is
+// $12.99 and 1299 JPY is ¥1299. Amounts are only comparable within a currency.
+type Money struct {
+	Amount   int64  `bson:"amount"`
+	Currency string `bson:"currency"`
+}
+
+// CurrencyExponent returns the number of decimal places of the minor unit of currency,
+// or 2 for a code ISO 4217 does not list.
+func CurrencyExponent(currency string) int {
+	if exp, ok := iso4217.MinorUnit(currency); ok {
+		return exp
+	}
+
+	return 2
+}
+
+// ParseAmount parses a decimal string such as "12.99" or "12,99" into minor units of
+// currency. It fails if s has more decimal places than the currency has.
+func ParseAmount(s, currency string) (int64, error) {
+	exp := CurrencyExponent(currency)
+
+	whole, frac, ok := strings.Cut(s, ".")
+	if !ok {
+		whole, frac, ok = strings.Cut(s, ",")
+	}
+
+	if ok && frac == "" || !digits(strings.TrimPrefix(whole, "-")) || frac != "" && !digits(frac) {
+		return 0, errors.New("must be a decimal number")
+	}
+
+	if len(frac) > exp {
+		if exp == 0 {
+			return 0, fmt.Errorf("must be a whole number in %s", currency)
+		}
+
+		return 0, fmt.Errorf("must have at most %d decimal places in %s", exp, currency)
+	}
+
+	amount, err := strconv.ParseInt(whole+frac+strings.Repeat("0", exp-len(frac)), 10, 64)
+	if err != nil {
+		return 0, errors.New("is out of range")
+	}
+
+	return amount, nil
+}
+
+func digits(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
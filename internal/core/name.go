@@ -0,0 +1,26 @@
+package core
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NameKey folds a product name, or a prefix of one, by lowercasing it, stripping
+// accents and collapsing runs of spaces. A trailing space is kept.
+func NameKey(name string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), name)
+	if err != nil {
+		folded = name
+	}
+
+	key := strings.Join(strings.Fields(strings.ToLower(folded)), " ")
+	if key != "" && strings.TrimRightFunc(name, unicode.IsSpace) != name {
+		key += " "
+	}
+
+	return key
+}
@@ -0,0 +1,31 @@
+package core
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	PriceHistoryDefaultSort  = "-fetched_at"
+	PriceHistorySortSafeList = []string{"fetched_at", "-fetched_at"}
+)
+
+type PriceChange struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	ProductName string             `bson:"product_name"`
+	SKU         string             `bson:"sku,omitempty"`
+	OldPrice    Money              `bson:"old_price"`
+	NewPrice    Money              `bson:"new_price"`
+	FetchedAt   time.Time          `bson:"fetched_at"`
+	SourceURL   string             `bson:"source_url"`
+}
+
+// PriceHistoryFilter narrows the price history of one product, found by SKU if set and
+// by name otherwise. Zero From or To leaves that side of the time range open.
+type PriceHistoryFilter struct {
+	ProductName string
+	SKU         string
+	From        time.Time
+	To          time.Time
+}
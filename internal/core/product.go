@@ -1,16 +1,128 @@
 package core
 
 import (
+	"errors"
+	"strings"
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 var (
 	ProductDefaultSort  = "name"
-	ProductSortSafeList = []string{"name", "-name", "price", "-price"}
+	ProductSortSafeList = []string{
+		"name", "-name",
+		"sku", "-sku",
+		"price", "-price",
+		"updated_at", "-updated_at",
+		"price_change_count", "-price_change_count",
+	}
+)
+
+var (
+	ErrProductNotFound  = errors.New("product not found")
+	ErrInvalidPageToken = errors.New("invalid page token")
 )
 
+// Product is a catalog entry, stored under its SKU if it has one and its name
+// otherwise. SourceURL and LastRunID tell which fetch run last saw it.
 type Product struct {
-	ID    primitive.ObjectID `bson:"id" csv:"-"`
-	Name  string             `bson:"name" csv:"PRODUCT NAME"`
-	Price int                `bson:"price" csv:"PRICE"`
+	ID               primitive.ObjectID `bson:"_id,omitempty"`
+	SKU              string             `bson:"sku,omitempty"`
+	Name             string             `bson:"name"`
+	Price            Money              `bson:"price"`
+	PriceChangeCount int                `bson:"price_change_count"`
+	UpdatedAt        time.Time          `bson:"updated_at"`
+	SourceURL        string             `bson:"source_url,omitempty"`
+	LastRunID        primitive.ObjectID `bson:"last_run_id,omitempty"`
+	LastSeenAt       time.Time          `bson:"last_seen_at,omitempty"`
+	DiscontinuedAt   time.Time          `bson:"discontinued_at,omitempty"`
+}
+
+// Discontinued reports whether p has dropped out of its feed.
+func (p Product) Discontinued() bool {
+	return !p.DiscontinuedAt.IsZero()
+}
+
+// ProductFilter narrows the products listed; discontinued products are left out unless
+// IncludeDiscontinued is set. Price bounds are inclusive and share one currency.
+type ProductFilter struct {
+	IncludeDiscontinued bool
+	MinPrice            *Money
+	MaxPrice            *Money
+	NamePrefix          string
+	NameContains        string
+}
+
+// ProductCursor marks the last product of a page by the sort it was listed by and the
+// sort key and id of that product.
+type ProductCursor struct {
+	Sort string             `bson:"sort"`
+	Key  []interface{}      `bson:"key"`
+	ID   primitive.ObjectID `bson:"id"`
+}
+
+// NewProductCursor returns the cursor at p in the products sorted by sort, a
+// comma-separated list of values of ProductSortSafeList.
+func NewProductCursor(p Product, sort string) ProductCursor {
+	c := ProductCursor{Sort: sort, Key: []interface{}{}, ID: p.ID}
+	for _, column := range strings.Split(sort, ",") {
+		c.Key = append(c.Key, p.SortKey(strings.TrimPrefix(strings.TrimSpace(column), "-"))...)
+	}
+
+	return c
+}
+
+// SortKey returns the values p is sorted on for column, in order. Products without a
+// SKU sort as null.
+func (p Product) SortKey(column string) []interface{} {
+	switch column {
+	case "name":
+		return []interface{}{p.Name}
+	case "sku":
+		if p.SKU == "" {
+			return []interface{}{nil}
+		}
+
+		return []interface{}{p.SKU}
+	case "price":
+		return []interface{}{p.Price.Currency, p.Price.Amount}
+	case "updated_at":
+		return []interface{}{p.UpdatedAt}
+	case "price_change_count":
+		return []interface{}{p.PriceChangeCount}
+	default:
+		return nil
+	}
+}
+
+// FetchRun is one ingestion of a feed from SourceURL, started at StartedAt.
+type FetchRun struct {
+	ID        primitive.ObjectID
+	SourceURL string
+	StartedAt time.Time
+}
+
+// ProductKey identifies a product by its SKU, or by its name if it has none.
+type ProductKey struct {
+	SKU  string
+	Name string
+}
+
+// Key returns the key p is stored under.
+func (p Product) Key() ProductKey {
+	if p.SKU != "" {
+		return ProductKey{SKU: p.SKU}
+	}
+
+	return ProductKey{Name: p.Name}
+}
+
+// WriteResult counts what storing a batch of products did. Changes lists the price
+// changes behind Repriced.
+type WriteResult struct {
+	Inserted  int
+	Repriced  int
+	Unchanged int
+	Changes   []PriceChange
 }
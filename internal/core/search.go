@@ -0,0 +1,27 @@
+package core
+
+const (
+	// MaxSearchQueryLength is the longest search query, and name prefix to suggest
+	// names for, in characters.
+	MaxSearchQueryLength = 255
+	// DefaultSuggestLimit is the number of names suggested when none is asked for.
+	DefaultSuggestLimit = 10
+	// MaxSuggestLimit is the most names suggested at once.
+	MaxSuggestLimit = 50
+)
+
+// SearchResult is a product found by a search. Score is its relevance to the query,
+// higher being more relevant, and Highlights are the spans of its fields that matched.
+type SearchResult struct {
+	Product    Product
+	Score      float64
+	Highlights []Highlight
+}
+
+// Highlight is a span of the Field of a product, such as "name", matching a search
+// term. Start and End are character offsets, End being exclusive.
+type Highlight struct {
+	Field string
+	Start int
+	End   int
+}
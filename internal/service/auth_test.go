@@ -0,0 +1,106 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ernur-eskermes/product-store/internal/config"
+	"github.com/ernur-eskermes/product-store/internal/core"
+	"github.com/ernur-eskermes/product-store/internal/service"
+	mock_service "github.com/ernur-eskermes/product-store/internal/service/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProduct_FetchAuth(t *testing.T) {
+	b := []byte("PRODUCT NAME;PRICE\nTest Product;1000\n")
+
+	cfg := testFetchConfig
+	cfg.Secrets = map[string]config.FeedSecret{
+		"supplier": {BearerToken: "secret-token"},
+	}
+
+	cases := []struct {
+		name       string
+		auth       *core.FeedAuth
+		expHeaders map[string]string
+		expErr     error
+	}{
+		{
+			name:       "basic",
+			auth:       &core.FeedAuth{Username: "user", Password: "pass"},
+			expHeaders: map[string]string{"Authorization": "Basic dXNlcjpwYXNz"},
+		},
+		{
+			name:       "bearer",
+			auth:       &core.FeedAuth{BearerToken: "token"},
+			expHeaders: map[string]string{"Authorization": "Bearer token"},
+		},
+		{
+			name:       "header",
+			auth:       &core.FeedAuth{HeaderName: "X-Api-Key", HeaderValue: "key"},
+			expHeaders: map[string]string{"X-Api-Key": "key", "Authorization": ""},
+		},
+		{
+			name:       "named_secret",
+			auth:       &core.FeedAuth{Secret: "supplier"},
+			expHeaders: map[string]string{"Authorization": "Bearer secret-token"},
+		},
+		{
+			name:   "unknown_secret",
+			auth:   &core.FeedAuth{Secret: "other"},
+			expErr: core.ErrUnknownSecret,
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			defer mockCtl.Finish()
+
+			httpClient := mock_service.NewMockHTTPClient(mockCtl)
+			productRepo := mock_service.NewMockProductStorage(mockCtl)
+			feedCacheRepo := mock_service.NewMockFeedCacheStorage(mockCtl)
+			productService := service.NewProductService(productRepo, mock_service.NewMockPriceHistoryStorage(mockCtl), feedCacheRepo, httpClient, cfg, testPageTokenKey)
+
+			feedCacheRepo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(core.FeedCache{}, nil).AnyTimes()
+
+			if s.expErr == nil {
+				httpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					for k, v := range s.expHeaders {
+						require.Equal(t, v, req.Header.Get(k))
+					}
+
+					return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(b))}, nil
+				})
+				productRepo.EXPECT().UpdateOrCreate(gomock.Any(), gomock.Any(), gomock.Any()).Return(core.WriteResult{Inserted: 1}, nil)
+				feedCacheRepo.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c core.FeedCache) error {
+					require.Nil(t, c.Options.Auth)
+
+					return nil
+				})
+			}
+
+			_, err := productService.Fetch(context.Background(), "https://some-url.com", core.FetchOptions{Auth: s.auth}, nil)
+			if s.expErr != nil {
+				require.True(t, errors.Is(err, s.expErr))
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFeedAuth_String(t *testing.T) {
+	auth := core.FeedAuth{Username: "user", Password: "pass", BearerToken: "token", HeaderValue: "key"}
+
+	require.NotContains(t, auth.String(), "pass")
+	require.NotContains(t, auth.String(), "token")
+	require.NotContains(t, auth.String(), "key")
+}
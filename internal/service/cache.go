@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+)
+
+// spooledFeed is a feed body copied to a temporary file. Hash is the hex SHA-256 of
+// the body.
+type spooledFeed struct {
+	*os.File
+	Size int64
+	Hash string
+}
+
+// spooledBody is a feed body already spooled by its opener. Closing it removes the
+// file.
+type spooledBody struct {
+	*spooledFeed
+	cleanup func()
+}
+
+func (b *spooledBody) Close() error {
+	b.cleanup()
+
+	return nil
+}
+
+// spool copies in to a temporary file, so that the body can be hashed before it is
+// ingested and zip archives, whose central directory sits at the end, can be read.
+// The returned cleanup removes the file.
+func spool(in io.Reader) (*spooledFeed, func(), error) {
+	f, err := os.CreateTemp("", "feed-*")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cleanup := func() {
+		f.Close()
+		os.Remove(f.Name())
+	}
+
+	h := sha256.New()
+
+	size, err := io.Copy(io.MultiWriter(f, h), in)
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+
+	if err != nil {
+		cleanup()
+
+		return nil, nil, err
+	}
+
+	return &spooledFeed{File: f, Size: size, Hash: hex.EncodeToString(h.Sum(nil))}, cleanup, nil
+}
+
+// setConditionalHeaders makes req conditional on the validators of the last fetch.
+func setConditionalHeaders(req *http.Request, cache core.FeedCache) {
+	if cache.ETag != "" {
+		req.Header.Set("If-None-Match", cache.ETag)
+	}
+
+	if cache.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cache.LastModified)
+	}
+}
+
+// cacheFor returns what is cached for url, unless it was fetched with other options,
+// in which case the feed has to be ingested again.
+func (s *ProductService) cacheFor(ctx context.Context, url string, opts core.FetchOptions) (core.FeedCache, error) {
+	cache, err := s.feedCacheRepo.Get(ctx, url)
+	if err != nil {
+		return core.FeedCache{}, err
+	}
+
+	if !sameFetchOptions(cache.Options, opts) {
+		return core.FeedCache{}, nil
+	}
+
+	return cache, nil
+}
+
+// cacheOptions returns opts as remembered in the cache: without the credentials, which
+// are not needed to tell whether the cache applies.
+func cacheOptions(opts core.FetchOptions) core.FetchOptions {
+	opts.Auth = nil
+
+	return opts
+}
+
+// sameFetchOptions reports whether a feed read with a is stored just as one read with
+// b. Switching to or from snapshots counts as a change, since a snapshot also
+// discontinues products.
+func sameFetchOptions(a, b core.FetchOptions) bool {
+	if a.Format != b.Format || a.Snapshot != b.Snapshot || a.Currency != b.Currency || a.CSV.Delimiter != b.CSV.Delimiter || a.CSV.Quote != b.CSV.Quote || a.CSV.NoHeader != b.CSV.NoHeader {
+		return false
+	}
+
+	if len(a.CSV.Columns) != len(b.CSV.Columns) {
+		return false
+	}
+
+	for k, v := range a.CSV.Columns {
+		if b.CSV.Columns[k] != v {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,132 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	"github.com/ernur-eskermes/product-store/internal/service"
+	mock_service "github.com/ernur-eskermes/product-store/internal/service/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProduct_FetchConditional(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage, c *mock_service.MockFeedCacheStorage)
+
+	b := []byte("PRODUCT NAME;PRICE\nTest Product;1000\n")
+	sum := sha256.Sum256(b)
+	hash := hex.EncodeToString(sum[:])
+
+	cached := core.FeedCache{
+		URL:          "https://some-url.com",
+		ETag:         `"v1"`,
+		LastModified: "Fri, 01 Jul 2022 10:00:00 GMT",
+		ContentHash:  hash,
+	}
+
+	respond := func(r *mock_service.MockHTTPClient, status int, body []byte, expHeaders map[string]string) {
+		r.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			for k, v := range expHeaders {
+				require.Equal(t, v, req.Header.Get(k))
+			}
+
+			header := http.Header{}
+			header.Set("ETag", `"v2"`)
+
+			return &http.Response{StatusCode: status, Header: header, Body: ioutil.NopCloser(bytes.NewReader(body))}, nil
+		})
+	}
+
+	cases := []struct {
+		name         string
+		opts         core.FetchOptions
+		expSkipped   core.FetchSkip
+		mockBehavior mockBehavior
+	}{
+		{
+			name:       "not_modified",
+			expSkipped: core.FetchSkipNotModified,
+			mockBehavior: func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage, c *mock_service.MockFeedCacheStorage) {
+				c.EXPECT().Get(gomock.Any(), "https://some-url.com").Return(cached, nil)
+				respond(r, http.StatusNotModified, nil, map[string]string{"If-None-Match": `"v1"`, "If-Modified-Since": cached.LastModified})
+			},
+		},
+		{
+			name:       "same_content",
+			expSkipped: core.FetchSkipSameContent,
+			mockBehavior: func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage, c *mock_service.MockFeedCacheStorage) {
+				c.EXPECT().Get(gomock.Any(), "https://some-url.com").Return(cached, nil)
+				respond(r, http.StatusOK, b, nil)
+				c.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, cache core.FeedCache) error {
+					require.Equal(t, `"v2"`, cache.ETag)
+					require.Equal(t, hash, cache.ContentHash)
+
+					return nil
+				})
+			},
+		},
+		{
+			name: "changed_content",
+			mockBehavior: func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage, c *mock_service.MockFeedCacheStorage) {
+				stale := cached
+				stale.ContentHash = "stale"
+
+				c.EXPECT().Get(gomock.Any(), "https://some-url.com").Return(stale, nil)
+				respond(r, http.StatusOK, b, nil)
+				p.EXPECT().UpdateOrCreate(gomock.Any(), gomock.Any(), gomock.Any()).Return(core.WriteResult{Repriced: 1}, nil)
+				c.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, cache core.FeedCache) error {
+					require.Equal(t, "https://some-url.com", cache.URL)
+					require.Equal(t, `"v2"`, cache.ETag)
+					require.Equal(t, hash, cache.ContentHash)
+					require.False(t, cache.FetchedAt.IsZero())
+
+					return nil
+				})
+			},
+		},
+		{
+			name: "cached_with_other_options",
+			opts: core.FetchOptions{CSV: core.CSVDialect{Delimiter: ","}},
+			mockBehavior: func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage, c *mock_service.MockFeedCacheStorage) {
+				c.EXPECT().Get(gomock.Any(), "https://some-url.com").Return(cached, nil)
+				respond(r, http.StatusOK, []byte("PRODUCT NAME,PRICE\nTest Product,1000\n"), map[string]string{"If-None-Match": "", "If-Modified-Since": ""})
+				p.EXPECT().UpdateOrCreate(gomock.Any(), gomock.Any(), gomock.Any()).Return(core.WriteResult{Unchanged: 1}, nil)
+				c.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "dry_run",
+			opts: core.FetchOptions{DryRun: true},
+			mockBehavior: func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage, c *mock_service.MockFeedCacheStorage) {
+				respond(r, http.StatusOK, b, map[string]string{"If-None-Match": ""})
+				p.EXPECT().GetPrices(gomock.Any(), "https://some-url.com", []core.Product{{Name: "Test Product", Price: dollars(1000)}}).Return(map[core.ProductKey]core.Money{{Name: "Test Product"}: dollars(1000)}, nil)
+				p.EXPECT().Scan(gomock.Any(), "https://some-url.com", gomock.Any()).DoAndReturn(scan([]core.Product{{Name: "Test Product", Price: dollars(1000), SourceURL: "https://some-url.com"}}))
+			},
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			defer mockCtl.Finish()
+
+			httpClient := mock_service.NewMockHTTPClient(mockCtl)
+			productRepo := mock_service.NewMockProductStorage(mockCtl)
+			feedCacheRepo := mock_service.NewMockFeedCacheStorage(mockCtl)
+
+			productService := service.NewProductService(productRepo, mock_service.NewMockPriceHistoryStorage(mockCtl), feedCacheRepo, httpClient, testFetchConfig, testPageTokenKey)
+
+			s.mockBehavior(httpClient, productRepo, feedCacheRepo)
+
+			report, err := productService.Fetch(context.Background(), "https://some-url.com", s.opts, nil)
+			require.NoError(t, err)
+			require.Equal(t, s.expSkipped, report.Skipped)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+)
+
+type compression string
+
+const (
+	compressionNone  compression = ""
+	compressionGzip  compression = "gzip"
+	compressionBzip2 compression = "bzip2"
+	compressionZip   compression = "zip"
+)
+
+var (
+	compressionMagic = []struct {
+		magic       []byte
+		compression compression
+	}{
+		{[]byte{0x1f, 0x8b}, compressionGzip},
+		{[]byte("BZh"), compressionBzip2},
+		{[]byte("PK\x03\x04"), compressionZip},
+	}
+	contentEncodingCompressions = map[string]compression{
+		"gzip":   compressionGzip,
+		"x-gzip": compressionGzip,
+		"bzip2":  compressionBzip2,
+	}
+	extensionCompressions = map[string]compression{
+		".gz":   compressionGzip,
+		".gzip": compressionGzip,
+		".bz2":  compressionBzip2,
+		".zip":  compressionZip,
+	}
+)
+
+// detectCompression tells how a feed is compressed. The leading bytes of the payload
+// are trusted first, then the Content-Encoding header, then the extension of name.
+func detectCompression(in *bufio.Reader, contentEncoding, name string) compression {
+	head, _ := in.Peek(4)
+	for _, m := range compressionMagic {
+		if bytes.HasPrefix(head, m.magic) {
+			return m.compression
+		}
+	}
+
+	if c, ok := contentEncodingCompressions[strings.ToLower(strings.TrimSpace(contentEncoding))]; ok {
+		return c
+	}
+
+	return extensionCompression(name)
+}
+
+func extensionCompression(name string) compression {
+	return extensionCompressions[strings.ToLower(path.Ext(urlPath(name)))]
+}
+
+// trimCompressionExt strips a compression extension, so "products.csv.gz" is
+// recognised as a CSV feed.
+func trimCompressionExt(name string) string {
+	p := urlPath(name)
+	if _, ok := extensionCompressions[strings.ToLower(path.Ext(p))]; ok {
+		return strings.TrimSuffix(p, path.Ext(p))
+	}
+
+	return p
+}
+
+func urlPath(name string) string {
+	if u, err := url.Parse(name); err == nil {
+		return u.Path
+	}
+
+	return name
+}
+
+// decompressStream wraps in with a streaming decompressor. Zip archives need random
+// access and are read from the spooled feed instead.
+func decompressStream(in io.Reader, c compression) (io.Reader, error) {
+	switch c {
+	case compressionGzip:
+		return gzip.NewReader(in)
+	case compressionBzip2:
+		return bzip2.NewReader(in), nil
+	default:
+		return in, nil
+	}
+}
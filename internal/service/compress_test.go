@@ -0,0 +1,159 @@
+package service_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	mock_service "github.com/ernur-eskermes/product-store/internal/service/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipFeed(t *testing.T, body string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(body))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func zipFeed(t *testing.T, files map[string]string, names ...string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w := zip.NewWriter(&buf)
+	for _, name := range names {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+
+		_, err = f.Write([]byte(files[name]))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func TestProduct_FetchCompressed(t *testing.T) {
+	csvFeed := "PRODUCT NAME;PRICE\nApple;100\nPear;200\n"
+	products := []core.Product{{Name: "Apple", Price: dollars(100)}, {Name: "Pear", Price: dollars(200)}}
+
+	bz2Feed, err := base64.StdEncoding.DecodeString("QlpoOTFBWSZTWYezsy4AAAzfgAAQQABwCC4j1gAiBFAAIAAhqmjTanomaan6jUKADEaaaNF6GZClEBhGXBdnLvzdQQhbc4FY/F3JFOFCQh7OzLg=")
+	require.NoError(t, err)
+
+	cases := []struct {
+		name        string
+		url         string
+		header      http.Header
+		body        []byte
+		expResp     []core.Product
+		expProgress []int
+		expErr      string
+	}{
+		{
+			name:        "gzip_by_magic_bytes",
+			url:         "https://some-url.com/feed",
+			body:        gzipFeed(t, csvFeed),
+			expResp:     products,
+			expProgress: []int{2},
+		},
+		{
+			name:        "gzip_json_by_extension",
+			url:         "https://some-url.com/products.json.gz",
+			header:      http.Header{"Content-Type": {"application/gzip"}},
+			body:        gzipFeed(t, `[{"name": "Apple", "price": 100}, {"name": "Pear", "price": 200}]`),
+			expResp:     products,
+			expProgress: []int{2},
+		},
+		{
+			name:        "bzip2",
+			url:         "https://some-url.com/products.csv.bz2",
+			body:        bz2Feed,
+			expResp:     products,
+			expProgress: []int{2},
+		},
+		{
+			name: "zip_with_several_entries",
+			url:  "https://some-url.com/feed.zip",
+			body: zipFeed(t, map[string]string{
+				"a/":           "",
+				"a/fruit.csv":  csvFeed,
+				"berries.json": `[{"name": "Cherry", "price": 300}]`,
+			}, "a/", "a/fruit.csv", "berries.json"),
+			expResp:     append(products, core.Product{Name: "Cherry", Price: dollars(300)}),
+			expProgress: []int{2, 3},
+		},
+		{
+			name:    "gzip_content_encoding_with_broken_payload",
+			url:     "https://some-url.com/feed",
+			header:  http.Header{"Content-Encoding": {"gzip"}},
+			body:    []byte(csvFeed),
+			expErr:  "gzip: invalid header",
+			expResp: []core.Product{},
+		},
+		{
+			name:    "error_in_zip_entry",
+			url:     "https://some-url.com/feed.zip",
+			body:    zipFeed(t, map[string]string{"bad.csv": "name;cost\nApple;100\n"}, "bad.csv"),
+			expErr:  "bad.csv: column \"PRICE\" not found in csv header",
+			expResp: []core.Product{},
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			defer mockCtl.Finish()
+
+			httpClient := mock_service.NewMockHTTPClient(mockCtl)
+			productService, productRepo, _ := mockProductService(t, httpClient)
+
+			header := s.header
+			if header == nil {
+				header = http.Header{}
+			}
+
+			httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{StatusCode: http.StatusOK, Header: header, Body: ioutil.NopCloser(bytes.NewReader(s.body))}, nil)
+
+			stored := make([]core.Product, 0)
+			productRepo.EXPECT().UpdateOrCreate(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, products []core.Product, _ interface{}) (core.WriteResult, error) {
+					stored = append(stored, products...)
+
+					return core.WriteResult{Inserted: len(products)}, nil
+				},
+			).AnyTimes()
+
+			var progress []int
+
+			_, err := productService.Fetch(context.Background(), s.url, core.FetchOptions{}, func(rows int) {
+				progress = append(progress, rows)
+			})
+			if s.expErr != "" {
+				var feedErr *core.FeedError
+
+				require.EqualError(t, err, s.expErr)
+				require.ErrorAs(t, err, &feedErr)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, s.expProgress, progress)
+			}
+
+			require.Equal(t, s.expResp, stored)
+		})
+	}
+}
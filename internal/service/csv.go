@@ -0,0 +1,252 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+)
+
+var ErrEmptyCSVFile = errors.New("empty csv file given")
+
+// csvDecoder reads products from a CSV feed one row at a time. Prices are in currency
+// unless a row has a currency column.
+type csvDecoder struct {
+	r        *csv.Reader
+	quote    rune
+	currency string
+	columns  map[int]string
+	// labels holds the header name, or the 1-based position, of every mapped column.
+	labels map[int]string
+	line   int
+}
+
+func newCSVDecoder(in io.Reader, dialect core.CSVDialect, currency string) (*csvDecoder, error) {
+	delimiter, _ := utf8.DecodeRuneInString(dialect.Delimiter)
+	if dialect.Delimiter == "" {
+		delimiter, _ = utf8.DecodeRuneInString(core.DefaultCSVDelimiter)
+	}
+
+	quote := '"'
+	if dialect.Quote != "" && dialect.Quote != `"` {
+		quote = rune(dialect.Quote[0])
+		in = &quoteSwapReader{r: in, quote: dialect.Quote[0]}
+	}
+
+	r := csv.NewReader(in)
+	r.Comma = delimiter
+	r.FieldsPerRecord = -1
+	r.ReuseRecord = true
+
+	d := &csvDecoder{r: r, quote: quote, currency: currency}
+
+	columns := dialect.Columns
+
+	var optional map[string]string
+	if len(columns) == 0 {
+		columns = core.DefaultCSVColumns
+		optional = core.OptionalCSVColumns
+
+		if dialect.NoHeader {
+			columns = core.DefaultHeaderlessCSVColumns
+		}
+	}
+
+	var err error
+	if dialect.NoHeader {
+		d.columns, d.labels, err = positionColumns(columns)
+	} else {
+		d.columns, d.labels, err = d.headerColumns(columns, optional)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// Decode returns the next product in the feed, or io.EOF once the feed is exhausted.
+func (d *csvDecoder) Decode() (core.Product, error) {
+	record, err := d.r.Read()
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) && recoverableCSVError(parseErr.Err) {
+		d.line = parseErr.StartLine
+
+		return core.Product{}, &core.RowError{Line: d.line, Reason: parseErr.Err.Error()}
+	}
+
+	if err != nil {
+		return core.Product{}, err
+	}
+
+	d.line, _ = d.r.FieldPos(0)
+
+	var product core.Product
+	var price, currency string
+
+	for i, field := range d.columns {
+		var value string
+		if i < len(record) {
+			value = d.unquote(record[i])
+		}
+
+		switch field {
+		case core.ProductFieldName:
+			product.Name = strings.TrimSpace(value)
+		case core.ProductFieldSKU:
+			product.SKU = strings.TrimSpace(value)
+		case core.ProductFieldPrice:
+			price = value
+		case core.ProductFieldCurrency:
+			currency = value
+		}
+	}
+
+	var field string
+	if product.Price, field, err = parsePrice(price, currency, d.currency); err != nil {
+		return core.Product{}, &core.RowError{Line: d.line, Column: d.Column(field), Reason: err.Error()}
+	}
+
+	return product, nil
+}
+
+// recoverableCSVError reports whether err spoils only the record it was found in. The
+// reader has consumed that line and reads on from the next, so the row is rejected.
+// A quoted field missing its closing quote may run to the end of the feed instead, so
+// that, like other reader errors, fails the feed.
+func recoverableCSVError(err error) bool {
+	return errors.Is(err, csv.ErrBareQuote) || errors.Is(err, csv.ErrFieldCount)
+}
+
+func (d *csvDecoder) Line() int {
+	return d.line
+}
+
+func (d *csvDecoder) Column(field string) string {
+	for i, f := range d.columns {
+		if f == field {
+			return d.labels[i]
+		}
+	}
+
+	return field
+}
+
+// headerColumns finds the columns of the header row. Every column in columns must be
+// there, while those in optional are mapped only if they are.
+func (d *csvDecoder) headerColumns(columns, optional map[string]string) (map[int]string, map[int]string, error) {
+	header, err := d.r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, ErrEmptyCSVFile
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make([]string, 0, len(columns)+len(optional))
+	for name := range columns {
+		names = append(names, name)
+	}
+
+	for name := range optional {
+		if _, ok := columns[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	res := make(map[int]string, len(names))
+	labels := make(map[int]string, len(names))
+
+	for _, name := range names {
+		field, required := columns[name]
+		if !required {
+			field = optional[name]
+		}
+
+		found := false
+
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(d.unquote(h), "\ufeff")), name) {
+				res[i] = field
+				labels[i] = name
+				found = true
+
+				break
+			}
+		}
+
+		if !found && required {
+			return nil, nil, fmt.Errorf("column %q not found in csv header", name)
+		}
+	}
+
+	return res, labels, nil
+}
+
+func positionColumns(columns map[string]string) (map[int]string, map[int]string, error) {
+	res := make(map[int]string, len(columns))
+	labels := make(map[int]string, len(columns))
+
+	for position, field := range columns {
+		i, err := strconv.Atoi(position)
+		if err != nil || i < 1 {
+			return nil, nil, fmt.Errorf("invalid column position %q", position)
+		}
+
+		res[i-1] = field
+		labels[i-1] = position
+	}
+
+	return res, labels, nil
+}
+
+func (d *csvDecoder) unquote(s string) string {
+	if d.quote == '"' {
+		return s
+	}
+
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case d.quote:
+			return '"'
+		case '"':
+			return d.quote
+		default:
+			return r
+		}
+	}, s)
+}
+
+// quoteSwapReader exchanges quote and '"' bytes, so encoding/csv, which only knows
+// about '"', can parse feeds quoted with another character. Values are swapped back
+// by csvDecoder.unquote.
+type quoteSwapReader struct {
+	r     io.Reader
+	quote byte
+}
+
+func (q *quoteSwapReader) Read(p []byte) (int, error) {
+	n, err := q.r.Read(p)
+
+	for i, b := range p[:n] {
+		switch b {
+		case q.quote:
+			p[i] = '"'
+		case '"':
+			p[i] = q.quote
+		}
+	}
+
+	return n, err
+}
@@ -0,0 +1,89 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	mock_service "github.com/ernur-eskermes/product-store/internal/service/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProduct_FetchCSVDialect(t *testing.T) {
+	cases := []struct {
+		name    string
+		body    string
+		dialect core.CSVDialect
+		expResp []core.Product
+		expErr  string
+	}{
+		{
+			name:    "default_dialect",
+			body:    "PRODUCT NAME;PRICE\nApple;100\n",
+			expResp: []core.Product{{Name: "Apple", Price: dollars(100)}},
+		},
+		{
+			name:    "default_dialect_with_sku",
+			body:    "PRICE;SKU;PRODUCT NAME\n100; A-1 ;Apple\n200;;Pear\n",
+			expResp: []core.Product{{SKU: "A-1", Name: "Apple", Price: dollars(100)}, {Name: "Pear", Price: dollars(200)}},
+		},
+		{
+			name:    "comma_with_renamed_columns",
+			body:    "sku,Title,Cost\n1,\"Apple, green\",100\n",
+			dialect: core.CSVDialect{Delimiter: ",", Columns: map[string]string{"title": "name", "COST": "price"}},
+			expResp: []core.Product{{Name: "Apple, green", Price: dollars(100)}},
+		},
+		{
+			name:    "tab_without_header",
+			body:    "100\tApple\n200\tPear\n",
+			dialect: core.CSVDialect{Delimiter: "\t", NoHeader: true, Columns: map[string]string{"2": "name", "1": "price"}},
+			expResp: []core.Product{{Name: "Apple", Price: dollars(100)}, {Name: "Pear", Price: dollars(200)}},
+		},
+		{
+			name:    "pipe_with_custom_quote",
+			body:    "PRODUCT NAME|PRICE\n'The \"Best\" | only'|100\n'It''s'|200\n",
+			dialect: core.CSVDialect{Delimiter: "|", Quote: "'"},
+			expResp: []core.Product{{Name: "The \"Best\" | only", Price: dollars(100)}, {Name: "It's", Price: dollars(200)}},
+		},
+		{
+			name:    "missing_column",
+			body:    "name;cost\nApple;100\n",
+			expErr:  "column \"PRICE\" not found in csv header",
+			expResp: []core.Product{},
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			defer mockCtl.Finish()
+
+			httpClient := mock_service.NewMockHTTPClient(mockCtl)
+			productService, productRepo, _ := mockProductService(t, httpClient)
+
+			httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte(s.body)))}, nil)
+
+			stored := make([]core.Product, 0)
+			productRepo.EXPECT().UpdateOrCreate(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, products []core.Product, _ interface{}) (core.WriteResult, error) {
+					stored = append(stored, products...)
+
+					return core.WriteResult{Inserted: len(products)}, nil
+				},
+			).AnyTimes()
+
+			_, err := productService.Fetch(context.Background(), "https://some-url.com", core.FetchOptions{CSV: s.dialect}, nil)
+			if s.expErr != "" {
+				require.EqualError(t, err, s.expErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, s.expResp, stored)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+)
+
+func newCatalogDiff() *core.CatalogDiff {
+	return &core.CatalogDiff{
+		Created:   core.DiffGroup{Samples: []core.ProductDiff{}},
+		Repriced:  core.DiffGroup{Samples: []core.ProductDiff{}},
+		Unchanged: core.DiffGroup{Samples: []core.ProductDiff{}},
+		Missing:   core.DiffGroup{Samples: []core.ProductDiff{}},
+	}
+}
+
+// diff sorts a batch of feed products of run into the groups of d by comparing them
+// with the catalog.
+func (s *ProductService) diff(ctx context.Context, run core.FetchRun, d *core.CatalogDiff, products []core.Product) error {
+	prices, err := s.repo.GetPrices(ctx, run.SourceURL, products)
+	if err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		oldPrice, ok := prices[product.Key()]
+
+		switch {
+		case !ok:
+			s.addDiff(&d.Created, core.ProductDiff{SKU: product.SKU, Name: product.Name, NewPrice: product.Price})
+		case oldPrice != product.Price:
+			s.addDiff(&d.Repriced, core.ProductDiff{SKU: product.SKU, Name: product.Name, OldPrice: oldPrice, NewPrice: product.Price})
+		default:
+			s.addDiff(&d.Unchanged, core.ProductDiff{SKU: product.SKU, Name: product.Name, OldPrice: oldPrice, NewPrice: product.Price})
+		}
+	}
+
+	return nil
+}
+
+// diffMissing fills the missing group once the whole feed has been read: the products
+// last stored from the source of the run, leaving discontinued products aside, that
+// the feed did not have, which a snapshot would discontinue. They are counted from the
+// catalog itself, as the feed may also have products that were discontinued. Nothing
+// is counted when the feed had more products than the validator remembers.
+func (s *ProductService) diffMissing(ctx context.Context, in *ingestion) error {
+	if in.validator.untracked {
+		return nil
+	}
+
+	d := in.report.Diff
+
+	return s.repo.Scan(ctx, in.run.SourceURL, func(product core.Product) bool {
+		if _, ok := in.validator.seen[product.Key()]; !ok {
+			s.addDiff(&d.Missing, core.ProductDiff{SKU: product.SKU, Name: product.Name, OldPrice: product.Price})
+		}
+
+		return true
+	})
+}
+
+func (s *ProductService) addDiff(g *core.DiffGroup, p core.ProductDiff) {
+	g.Count++
+
+	if len(g.Samples) < s.cfg.DiffSampleSize {
+		g.Samples = append(g.Samples, p)
+	}
+}
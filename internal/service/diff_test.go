@@ -0,0 +1,140 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	mock_service "github.com/ernur-eskermes/product-store/internal/service/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProduct_FetchDryRun(t *testing.T) {
+	type mockBehavior func(p *mock_service.MockProductStorage)
+
+	// Grape and Mango come from another feed, so they are not missing from this one.
+	catalog := []core.Product{
+		{Name: "Apple", Price: dollars(100), SourceURL: "https://some-url.com"},
+		{Name: "Cherry", Price: dollars(300), SourceURL: "https://some-url.com"},
+		{Name: "Grape", Price: dollars(500), SourceURL: "https://other-url.com"},
+		{Name: "Kiwi", Price: dollars(400), SourceURL: "https://some-url.com"},
+		{Name: "Mango", Price: dollars(600), SourceURL: "https://other-url.com"},
+		{Name: "Pear", Price: dollars(200), SourceURL: "https://some-url.com"},
+	}
+	body := "PRODUCT NAME;PRICE\nApple;100\nPear;250\nPlum;50\n"
+
+	cases := []struct {
+		name         string
+		expReport    core.FetchReport
+		expErr       string
+		mockBehavior mockBehavior
+	}{
+		{
+			name: "test_ok",
+			expReport: core.FetchReport{
+				RowsRead:     3,
+				RowsAccepted: 3,
+				RejectedRows: []core.RowError{},
+				Diff: &core.CatalogDiff{
+					Created:   core.DiffGroup{Count: 1, Samples: []core.ProductDiff{{Name: "Plum", NewPrice: dollars(50)}}},
+					Repriced:  core.DiffGroup{Count: 1, Samples: []core.ProductDiff{{Name: "Pear", OldPrice: dollars(200), NewPrice: dollars(250)}}},
+					Unchanged: core.DiffGroup{Count: 1, Samples: []core.ProductDiff{{Name: "Apple", OldPrice: dollars(100), NewPrice: dollars(100)}}},
+					Missing:   core.DiffGroup{Count: 2, Samples: []core.ProductDiff{{Name: "Cherry", OldPrice: dollars(300)}}},
+				},
+			},
+			mockBehavior: func(p *mock_service.MockProductStorage) {
+				gomock.InOrder(
+					p.EXPECT().GetPrices(gomock.Any(), "https://some-url.com", []core.Product{{Name: "Apple", Price: dollars(100)}, {Name: "Pear", Price: dollars(250)}}).Return(map[core.ProductKey]core.Money{{Name: "Apple"}: dollars(100), {Name: "Pear"}: dollars(200)}, nil),
+					p.EXPECT().GetPrices(gomock.Any(), "https://some-url.com", []core.Product{{Name: "Plum", Price: dollars(50)}}).Return(map[core.ProductKey]core.Money{}, nil),
+				)
+				p.EXPECT().Scan(gomock.Any(), "https://some-url.com", gomock.Any()).DoAndReturn(scan(catalog))
+			},
+		},
+		{
+			name: "discontinued_product_relisted",
+			expReport: core.FetchReport{
+				RowsRead:     3,
+				RowsAccepted: 3,
+				RejectedRows: []core.RowError{},
+				Diff: &core.CatalogDiff{
+					Created:   core.DiffGroup{Samples: []core.ProductDiff{}},
+					Repriced:  core.DiffGroup{Count: 1, Samples: []core.ProductDiff{{Name: "Pear", OldPrice: dollars(200), NewPrice: dollars(250)}}},
+					Unchanged: core.DiffGroup{Count: 2, Samples: []core.ProductDiff{{Name: "Apple", OldPrice: dollars(100), NewPrice: dollars(100)}}},
+					Missing:   core.DiffGroup{Count: 2, Samples: []core.ProductDiff{{Name: "Cherry", OldPrice: dollars(300)}}},
+				},
+			},
+			mockBehavior: func(p *mock_service.MockProductStorage) {
+				// Plum is discontinued, so the scan of the catalog leaves it out.
+				gomock.InOrder(
+					p.EXPECT().GetPrices(gomock.Any(), gomock.Any(), gomock.Any()).Return(map[core.ProductKey]core.Money{{Name: "Apple"}: dollars(100), {Name: "Pear"}: dollars(200)}, nil),
+					p.EXPECT().GetPrices(gomock.Any(), gomock.Any(), gomock.Any()).Return(map[core.ProductKey]core.Money{{Name: "Plum"}: dollars(50)}, nil),
+				)
+				p.EXPECT().Scan(gomock.Any(), "https://some-url.com", gomock.Any()).DoAndReturn(scan(catalog))
+			},
+		},
+		{
+			name:   "error_when_reading_prices",
+			expErr: "error1",
+			expReport: core.FetchReport{
+				RowsRead:     2,
+				RejectedRows: []core.RowError{},
+				Diff: &core.CatalogDiff{
+					Created:   core.DiffGroup{Samples: []core.ProductDiff{}},
+					Repriced:  core.DiffGroup{Samples: []core.ProductDiff{}},
+					Unchanged: core.DiffGroup{Samples: []core.ProductDiff{}},
+					Missing:   core.DiffGroup{Samples: []core.ProductDiff{}},
+				},
+			},
+			mockBehavior: func(p *mock_service.MockProductStorage) {
+				p.EXPECT().GetPrices(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("error1"))
+			},
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			defer mockCtl.Finish()
+
+			httpClient := mock_service.NewMockHTTPClient(mockCtl)
+			productService, productRepo, _ := mockProductService(t, httpClient)
+
+			httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte(body)))}, nil)
+			s.mockBehavior(productRepo)
+
+			report, err := productService.Fetch(context.Background(), "https://some-url.com", core.FetchOptions{DryRun: true}, nil)
+			if s.expErr != "" {
+				require.EqualError(t, err, s.expErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Positive(t, report.Duration)
+			report.Duration = 0
+			s.expReport.BytesDownloaded = int64(len(body))
+			require.Equal(t, s.expReport, report)
+		})
+	}
+}
+
+// scan returns a Scan of products, which lists those of the source scanned.
+func scan(products []core.Product) func(context.Context, string, func(core.Product) bool) error {
+	return func(_ context.Context, sourceURL string, fn func(core.Product) bool) error {
+		for _, product := range products {
+			if product.SourceURL != sourceURL {
+				continue
+			}
+
+			if !fn(product) {
+				return nil
+			}
+		}
+
+		return nil
+	}
+}
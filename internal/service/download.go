@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/ernur-eskermes/product-store/internal/config"
+	"github.com/ernur-eskermes/product-store/internal/core"
+)
+
+// httpOpener opens http and https feeds, making the request conditional on the
+// validators of the last fetch and presenting the credentials requested.
+type httpOpener struct {
+	client HTTPClient
+	cfg    config.FetchConfig
+}
+
+func (o *httpOpener) Open(ctx context.Context, feedReq FeedRequest) (*Feed, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", feedReq.URL.String(), nil)
+	if err != nil {
+		return nil, &core.FeedError{Err: err}
+	}
+
+	setConditionalHeaders(req, feedReq.Cache)
+	setAuth(req, feedReq.Auth)
+
+	resp, body, err := o.download(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		return &Feed{NotModified: true}, nil
+	}
+
+	return &Feed{
+		Body:            body,
+		ContentType:     resp.Header.Get("Content-Type"),
+		ContentEncoding: resp.Header.Get("Content-Encoding"),
+		ETag:            resp.Header.Get("ETag"),
+		LastModified:    resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+func setAuth(req *http.Request, auth *core.FeedAuth) {
+	switch {
+	case auth == nil:
+	case auth.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+auth.BearerToken)
+	case auth.HeaderName != "":
+		req.Header.Set(auth.HeaderName, auth.HeaderValue)
+	case auth.Username != "":
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+}
+
+// download sends req and returns a response with a 2xx or 304 status, along with the
+// body of a 2xx response spooled to disk. Transient failures, including a connection
+// lost while the body is read, are retried up to the configured number of times with
+// exponential backoff, or after the delay the server asked for in Retry-After. The
+// configured download timeout bounds all attempts and the delays between them.
+func (o *httpOpener) download(req *http.Request) (*http.Response, *spooledBody, error) {
+	ctx := req.Context()
+
+	if o.cfg.DownloadTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, o.cfg.DownloadTimeout)
+		defer cancel()
+
+		req = req.WithContext(ctx)
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, body, err := o.attempt(req)
+		if err == nil {
+			return resp, body, nil
+		}
+
+		if attempt >= o.cfg.MaxRetries || !retryable(ctx, err) {
+			return nil, nil, err
+		}
+
+		timer := time.NewTimer(o.backoff(attempt, err))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, nil, &core.DownloadError{Err: ctx.Err()}
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt sends req once and spools the body of a 2xx response. A body cut off midway
+// is discarded, so that the next attempt starts afresh.
+func (o *httpOpener) attempt(req *http.Request) (*http.Response, *spooledBody, error) {
+	resp, err := o.send(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return resp, nil, nil
+	}
+
+	feed, cleanup, err := spool(resp.Body)
+	if err != nil {
+		return nil, nil, &core.DownloadError{Err: err}
+	}
+
+	return resp, &spooledBody{spooledFeed: feed, cleanup: cleanup}, nil
+}
+
+func (o *httpOpener) send(req *http.Request) (*http.Response, error) {
+	resp, err := o.client.Do(req.Clone(req.Context()))
+	if err != nil {
+		return nil, &core.DownloadError{Err: err}
+	}
+
+	if resp.StatusCode/100 == 2 || resp.StatusCode == http.StatusNotModified {
+		return resp, nil
+	}
+
+	resp.Body.Close()
+
+	return nil, &core.HTTPStatusError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var statusErr *core.HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Temporary()
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// backoff returns the delay before the retry following attempt. The delay doubles with
+// every attempt, up to the configured maximum, and is jittered so that fetches that
+// failed together do not retry together.
+func (o *httpOpener) backoff(attempt int, err error) time.Duration {
+	var statusErr *core.HTTPStatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		if statusErr.RetryAfter > o.cfg.RetryMaxDelay {
+			return o.cfg.RetryMaxDelay
+		}
+
+		return statusErr.RetryAfter
+	}
+
+	d := o.cfg.RetryBaseDelay << attempt
+	if d <= 0 || d > o.cfg.RetryMaxDelay {
+		d = o.cfg.RetryMaxDelay
+	}
+
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// parseRetryAfter reads a Retry-After header, given either in seconds or as an HTTP
+// date.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(v); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+
+	return 0
+}
@@ -0,0 +1,176 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	mock_service "github.com/ernur-eskermes/product-store/internal/service/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProduct_FetchRetries(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage)
+
+	b := []byte("PRODUCT NAME;PRICE\nTest Product;1000\n")
+
+	respond := func(status int, header http.Header) *http.Response {
+		return &http.Response{StatusCode: status, Header: header, Body: ioutil.NopCloser(bytes.NewReader(b))}
+	}
+
+	cases := []struct {
+		name         string
+		expErr       string
+		expStatus    int
+		mockBehavior mockBehavior
+	}{
+		{
+			name: "server_error_then_ok",
+			mockBehavior: func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage) {
+				gomock.InOrder(
+					r.EXPECT().Do(gomock.Any()).Return(respond(http.StatusServiceUnavailable, nil), nil),
+					r.EXPECT().Do(gomock.Any()).Return(respond(http.StatusOK, nil), nil),
+				)
+				p.EXPECT().UpdateOrCreate(gomock.Any(), gomock.Any(), gomock.Any()).Return(core.WriteResult{Inserted: 1}, nil)
+			},
+		},
+		{
+			name: "rate_limited_then_ok",
+			mockBehavior: func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage) {
+				gomock.InOrder(
+					r.EXPECT().Do(gomock.Any()).Return(respond(http.StatusTooManyRequests, http.Header{"Retry-After": {"120"}}), nil),
+					r.EXPECT().Do(gomock.Any()).Return(respond(http.StatusOK, nil), nil),
+				)
+				p.EXPECT().UpdateOrCreate(gomock.Any(), gomock.Any(), gomock.Any()).Return(core.WriteResult{Inserted: 1}, nil)
+			},
+		},
+		{
+			name: "connection_reset_then_ok",
+			mockBehavior: func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage) {
+				gomock.InOrder(
+					r.EXPECT().Do(gomock.Any()).Return(nil, &url.Error{Op: "Get", URL: "https://some-url.com", Err: syscall.ECONNRESET}),
+					r.EXPECT().Do(gomock.Any()).Return(respond(http.StatusOK, nil), nil),
+				)
+				p.EXPECT().UpdateOrCreate(gomock.Any(), gomock.Any(), gomock.Any()).Return(core.WriteResult{Inserted: 1}, nil)
+			},
+		},
+		{
+			name:      "retries_exhausted",
+			expErr:    "feed server responded 502 Bad Gateway",
+			expStatus: http.StatusBadGateway,
+			mockBehavior: func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage) {
+				r.EXPECT().Do(gomock.Any()).Return(respond(http.StatusBadGateway, nil), nil).Times(3)
+			},
+		},
+		{
+			name:      "not_found",
+			expErr:    "feed server responded 404 Not Found",
+			expStatus: http.StatusNotFound,
+			mockBehavior: func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage) {
+				r.EXPECT().Do(gomock.Any()).Return(respond(http.StatusNotFound, nil), nil)
+			},
+		},
+		{
+			name:   "permanent_error",
+			expErr: "error1",
+			mockBehavior: func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage) {
+				r.EXPECT().Do(gomock.Any()).Return(nil, errors.New("error1"))
+			},
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			defer mockCtl.Finish()
+
+			httpClient := mock_service.NewMockHTTPClient(mockCtl)
+			productService, productRepo, _ := mockProductService(t, httpClient)
+
+			s.mockBehavior(httpClient, productRepo)
+
+			_, err := productService.Fetch(context.Background(), "https://some-url.com", core.FetchOptions{}, nil)
+			if s.expErr == "" {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.EqualError(t, err, s.expErr)
+
+			var statusErr *core.HTTPStatusError
+			if s.expStatus != 0 {
+				require.ErrorAs(t, err, &statusErr)
+				require.Equal(t, s.expStatus, statusErr.StatusCode)
+			} else {
+				require.ErrorAs(t, err, new(*core.DownloadError))
+			}
+		})
+	}
+}
+
+func TestProduct_FetchRetriesTruncatedBody(t *testing.T) {
+	b := []byte("PRODUCT NAME;PRICE\nTest Product;1000\n")
+
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(b)))
+
+		// The first response is cut off midway through the body.
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Write(b[:len(b)/2])
+
+			return
+		}
+
+		w.Write(b)
+	}))
+	defer server.Close()
+
+	productService, productRepo, _ := mockProductService(t, server.Client())
+	productRepo.EXPECT().UpdateOrCreate(gomock.Any(), []core.Product{{Name: "Test Product", Price: dollars(1000)}}, gomock.Any()).Return(core.WriteResult{Inserted: 1}, nil)
+
+	report, err := productService.Fetch(context.Background(), server.URL, core.FetchOptions{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	require.Equal(t, 1, report.ProductsInserted)
+	require.Equal(t, int64(len(b)), report.BytesDownloaded)
+}
+
+func TestProduct_FetchRetriesStopAtDownloadTimeout(t *testing.T) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := testFetchConfig
+	cfg.MaxRetries = 1000
+	cfg.RetryBaseDelay = 10 * time.Millisecond
+	cfg.RetryMaxDelay = 10 * time.Millisecond
+	cfg.DownloadTimeout = 100 * time.Millisecond
+
+	productService, _, _ := mockProductServiceWithConfig(t, server.Client(), cfg)
+
+	start := time.Now()
+
+	_, err := productService.Fetch(context.Background(), server.URL, core.FetchOptions{}, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorAs(t, err, new(*core.DownloadError))
+	require.Less(t, time.Since(start), time.Second)
+	require.Less(t, atomic.LoadInt32(&requests), int32(cfg.MaxRetries))
+}
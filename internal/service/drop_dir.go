@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ernur-eskermes/product-store/internal/config"
+	"github.com/ernur-eskermes/product-store/internal/core"
+	"github.com/ernur-eskermes/product-store/pkg/logger"
+)
+
+type FeedUploader interface {
+	Upload(ctx context.Context, name string, r io.Reader, opts core.FetchOptions) (core.FetchReport, error)
+}
+
+// DropDirService ingests the feed files dropped into a directory. A file is picked up
+// once it has not been modified for the configured settle time, and is then moved to
+// the archive directory, or to the failed directory if it could not be ingested.
+// Hidden files are left alone, so a file can be written under a dotted name and
+// renamed once complete. Before a file is read, it is claimed by moving it into the
+// processing directory of this instance, so that instances sharing the drop directory
+// never ingest the same file.
+type DropDirService struct {
+	uploader FeedUploader
+	log      logger.Logger
+	cfg      config.DropConfig
+
+	wg sync.WaitGroup
+}
+
+func NewDropDirService(uploader FeedUploader, log logger.Logger, cfg config.DropConfig) *DropDirService {
+	if cfg.Dir != "" && cfg.ArchiveDir == "" {
+		cfg.ArchiveDir = filepath.Join(cfg.Dir, "archive")
+	}
+
+	if cfg.Dir != "" && cfg.FailedDir == "" {
+		cfg.FailedDir = filepath.Join(cfg.Dir, "failed")
+	}
+
+	if cfg.Dir != "" && cfg.ProcessingDir == "" {
+		cfg.ProcessingDir = filepath.Join(cfg.Dir, "processing", instanceName())
+	}
+
+	return &DropDirService{
+		uploader: uploader,
+		log:      log,
+		cfg:      cfg,
+	}
+}
+
+// Start launches the watcher, unless no drop directory is configured. It stops once ctx
+// is cancelled; Wait blocks until it has returned.
+func (s *DropDirService) Start(ctx context.Context) error {
+	if s.cfg.Dir == "" {
+		return nil
+	}
+
+	for _, dir := range []string{s.cfg.ArchiveDir, s.cfg.FailedDir, s.cfg.ProcessingDir} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	if err := s.release(); err != nil {
+		return err
+	}
+
+	s.wg.Add(1)
+
+	go s.watch(ctx)
+
+	return nil
+}
+
+func (s *DropDirService) Wait() {
+	s.wg.Wait()
+}
+
+func (s *DropDirService) watch(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.scan(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scan ingests the files of the drop directory that have settled, oldest name first.
+func (s *DropDirService) scan(ctx context.Context, now time.Time) {
+	entries, err := os.ReadDir(s.cfg.Dir)
+	if err != nil {
+		s.log.Error("read drop directory error", logger.Error(err))
+
+		return
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || now.Sub(info.ModTime()) < s.cfg.SettleTime {
+			continue
+		}
+
+		s.ingest(ctx, entry.Name(), now)
+	}
+}
+
+func (s *DropDirService) ingest(ctx context.Context, name string, now time.Time) {
+	dropped := filepath.Join(s.cfg.Dir, name)
+	path := filepath.Join(s.cfg.ProcessingDir, name)
+
+	// Renaming is atomic, so only one instance claims the file; the others no longer
+	// find it.
+	if err := os.Rename(dropped, path); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			s.log.Error("claim dropped feed error", logger.String("file", name), logger.Error(err))
+		}
+
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		s.log.Error("open dropped feed error", logger.String("file", name), logger.Error(err))
+
+		return
+	}
+
+	report, err := s.uploader.Upload(ctx, name, f, core.FetchOptions{})
+	f.Close()
+
+	// A file left half-read by a shutdown is handed back, to be picked up again.
+	if ctx.Err() != nil {
+		if err = os.Rename(path, dropped); err != nil {
+			s.log.Error("release dropped feed error", logger.String("file", name), logger.Error(err))
+		}
+
+		return
+	}
+
+	dest := s.cfg.ArchiveDir
+	if err != nil {
+		s.log.Error("ingest dropped feed error", logger.String("file", name), logger.Error(err))
+
+		dest = s.cfg.FailedDir
+	} else {
+		s.log.Info("dropped feed ingested", logger.String("file", name), logger.Int("rows_accepted", report.RowsAccepted), logger.Int("rows_rejected", report.RowsRejected))
+	}
+
+	// The timestamp keeps files dropped twice under the same name apart.
+	if err = os.Rename(path, filepath.Join(dest, now.UTC().Format("20060102T150405.000")+"-"+name)); err != nil {
+		s.log.Error("move dropped feed error", logger.String("file", name), logger.Error(err))
+	}
+}
+
+// release moves the files this instance had claimed but not ingested, when it stopped
+// before it could hand them back, to the drop directory again.
+func (s *DropDirService) release() error {
+	entries, err := os.ReadDir(s.cfg.ProcessingDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err = os.Rename(filepath.Join(s.cfg.ProcessingDir, entry.Name()), filepath.Join(s.cfg.Dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// instanceName names this instance after its host, which stays the same when it is
+// restarted, so that it finds the files it had claimed.
+func instanceName() string {
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return host
+	}
+
+	return strconv.Itoa(os.Getpid())
+}
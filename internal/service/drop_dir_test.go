@@ -0,0 +1,142 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ernur-eskermes/product-store/internal/config"
+	"github.com/ernur-eskermes/product-store/internal/core"
+	"github.com/ernur-eskermes/product-store/internal/service"
+	mock_service "github.com/ernur-eskermes/product-store/internal/service/mocks"
+	"github.com/ernur-eskermes/product-store/pkg/logger"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDropDir(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	dir := t.TempDir()
+	settled := time.Now().Add(-time.Hour)
+
+	for name, body := range map[string]string{"a.csv": "a", "b.csv": "b", ".c.csv": "c", "d.csv": "d"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+
+		// d.csv is still being written.
+		if name != "d.csv" {
+			require.NoError(t, os.Chtimes(path, settled, settled))
+		}
+	}
+
+	uploader := mock_service.NewMockFeedUploader(mockCtl)
+	uploader.EXPECT().Upload(gomock.Any(), "a.csv", gomock.Any(), core.FetchOptions{}).DoAndReturn(func(_ context.Context, _ string, r io.Reader, _ core.FetchOptions) (core.FetchReport, error) {
+		b, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, "a", string(b))
+
+		return core.FetchReport{RowsAccepted: 1}, nil
+	})
+	uploader.EXPECT().Upload(gomock.Any(), "b.csv", gomock.Any(), core.FetchOptions{}).Return(core.FetchReport{}, errors.New("error1"))
+
+	dropDirService := service.NewDropDirService(uploader, logger.New("error", "test"), config.DropConfig{
+		Dir:        dir,
+		Interval:   time.Hour,
+		SettleTime: time.Minute,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, dropDirService.Start(ctx))
+
+	names := func(dir string) []string {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+
+		res := make([]string, 0, len(entries))
+		for _, e := range entries {
+			if !e.IsDir() {
+				res = append(res, e.Name())
+			}
+		}
+
+		return res
+	}
+
+	require.Eventually(t, func() bool {
+		return len(names(filepath.Join(dir, "failed"))) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	dropDirService.Wait()
+
+	require.ElementsMatch(t, []string{".c.csv", "d.csv"}, names(dir))
+
+	archived := names(filepath.Join(dir, "archive"))
+	require.Len(t, archived, 1)
+	require.Regexp(t, `^\d{8}T\d{6}\.\d{3}-a\.csv$`, archived[0])
+
+	failed := names(filepath.Join(dir, "failed"))
+	require.Regexp(t, `-b\.csv$`, failed[0])
+}
+
+func TestDropDir_SharedDir(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	dir := t.TempDir()
+	settled := time.Now().Add(-time.Hour)
+
+	uploader := mock_service.NewMockFeedUploader(mockCtl)
+
+	// Every file is ingested by exactly one instance.
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("%02d.csv", i)
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(name), 0o600))
+		require.NoError(t, os.Chtimes(path, settled, settled))
+
+		uploader.EXPECT().Upload(gomock.Any(), name, gomock.Any(), core.FetchOptions{}).Return(core.FetchReport{}, nil)
+	}
+
+	// The first instance stopped while it was ingesting claimed.csv.
+	claimedDir := filepath.Join(dir, "processing", "first")
+	require.NoError(t, os.MkdirAll(claimedDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(claimedDir, "claimed.csv"), []byte("claimed"), 0o600))
+	require.NoError(t, os.Chtimes(filepath.Join(claimedDir, "claimed.csv"), settled, settled))
+	uploader.EXPECT().Upload(gomock.Any(), "claimed.csv", gomock.Any(), core.FetchOptions{}).Return(core.FetchReport{}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	services := make([]*service.DropDirService, 0, 2)
+	for _, instance := range []string{"first", "second"} {
+		s := service.NewDropDirService(uploader, logger.New("error", "test"), config.DropConfig{
+			Dir:           dir,
+			ProcessingDir: filepath.Join(dir, "processing", instance),
+			Interval:      time.Hour,
+			SettleTime:    time.Minute,
+		})
+		require.NoError(t, s.Start(ctx))
+
+		services = append(services, s)
+	}
+
+	require.Eventually(t, func() bool {
+		entries, err := os.ReadDir(filepath.Join(dir, "archive"))
+
+		return err == nil && len(entries) == 21
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+
+	for _, s := range services {
+		s.Wait()
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	"github.com/ernur-eskermes/product-store/pkg/iso4217"
+)
+
+// FeedDecoder reads products from a feed one at a time. Decode returns io.EOF once the
+// feed is exhausted, and a *core.RowError for a row that cannot be read but can be
+// skipped. Any other error means the rest of the feed cannot be read.
+type FeedDecoder interface {
+	Decode() (core.Product, error)
+	// Line returns the line, or the position for formats without lines, of the row
+	// last returned by Decode.
+	Line() int
+	// Column returns the name the feed uses for one of core.ProductFields.
+	Column(field string) string
+}
+
+type feedDecoderFactory func(in io.Reader, opts core.FetchOptions) (FeedDecoder, error)
+
+var feedDecoders = map[core.FeedFormat]feedDecoderFactory{
+	core.FeedFormatCSV: func(in io.Reader, opts core.FetchOptions) (FeedDecoder, error) {
+		return newCSVDecoder(in, opts.CSV, opts.Currency)
+	},
+	core.FeedFormatJSON: func(in io.Reader, opts core.FetchOptions) (FeedDecoder, error) {
+		return newJSONDecoder(in, opts.Currency), nil
+	},
+	core.FeedFormatNDJSON: func(in io.Reader, opts core.FetchOptions) (FeedDecoder, error) {
+		return newNDJSONDecoder(in, opts.Currency), nil
+	},
+	core.FeedFormatXML: func(in io.Reader, opts core.FetchOptions) (FeedDecoder, error) {
+		return newXMLDecoder(in, opts.Currency), nil
+	},
+}
+
+var (
+	contentTypeFormats = map[string]core.FeedFormat{
+		"text/csv":                core.FeedFormatCSV,
+		"application/csv":         core.FeedFormatCSV,
+		"application/json":        core.FeedFormatJSON,
+		"text/json":               core.FeedFormatJSON,
+		"application/x-ndjson":    core.FeedFormatNDJSON,
+		"application/ndjson":      core.FeedFormatNDJSON,
+		"application/jsonl":       core.FeedFormatNDJSON,
+		"application/x-jsonlines": core.FeedFormatNDJSON,
+		"application/xml":         core.FeedFormatXML,
+		"text/xml":                core.FeedFormatXML,
+	}
+	extensionFormats = map[string]core.FeedFormat{
+		".csv":    core.FeedFormatCSV,
+		".json":   core.FeedFormatJSON,
+		".ndjson": core.FeedFormatNDJSON,
+		".jsonl":  core.FeedFormatNDJSON,
+		".xml":    core.FeedFormatXML,
+	}
+)
+
+func newFeedDecoder(in io.Reader, format core.FeedFormat, opts core.FetchOptions) (FeedDecoder, error) {
+	factory, ok := feedDecoders[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported feed format %q", format)
+	}
+
+	return factory(in, opts)
+}
+
+// detectFeedFormat picks the format of a feed: an explicit format wins, then the
+// response Content-Type, then the extension of the feed URL, ignoring any compression
+// extension. Anything else is read as CSV, the format the service has always accepted.
+func detectFeedFormat(explicit core.FeedFormat, contentType, feedURL string) core.FeedFormat {
+	if explicit != "" {
+		return explicit
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if format, ok := contentTypeFormats[strings.ToLower(mediaType)]; ok {
+			return format
+		}
+	}
+
+	if format, ok := extensionFormats[strings.ToLower(path.Ext(trimCompressionExt(feedURL)))]; ok {
+		return format
+	}
+
+	return core.FeedFormatCSV
+}
+
+var (
+	errEmptyValue  = errors.New("must not be empty")
+	errNotADecimal = errors.New("must be a decimal number")
+)
+
+// parsePrice parses the decimal price of a row in its currency, or in defaultCurrency
+// if the row gives none. On failure, it returns the product field at fault as well.
+func parsePrice(price, currency, defaultCurrency string) (core.Money, string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		currency = defaultCurrency
+	} else if !iso4217.Valid(currency) {
+		return core.Money{}, core.ProductFieldCurrency, core.ErrInvalidCurrency
+	}
+
+	price = strings.TrimSpace(price)
+	if price == "" {
+		return core.Money{}, core.ProductFieldPrice, errEmptyValue
+	}
+
+	amount, err := core.ParseAmount(price, currency)
+	if err != nil {
+		return core.Money{}, core.ProductFieldPrice, err
+	}
+
+	return core.Money{Amount: amount, Currency: currency}, "", nil
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ernur-eskermes/product-store/internal/config"
+	"github.com/ernur-eskermes/product-store/internal/core"
+	"github.com/ernur-eskermes/product-store/pkg/filters"
+	"github.com/ernur-eskermes/product-store/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// dueSourcesLimit bounds how many due sources one scheduler tick picks up. Whatever is
+// left is picked up on the next tick.
+const dueSourcesLimit = 100
+
+type FeedSourceStorage interface {
+	Create(ctx context.Context, source core.FeedSource) (core.FeedSource, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (core.FeedSource, error)
+	GetAll(ctx context.Context, f *filters.Filters) ([]core.FeedSource, error)
+	GetTotalRecords(ctx context.Context) (int64, error)
+	Update(ctx context.Context, source core.FeedSource) (core.FeedSource, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	GetDue(ctx context.Context, now time.Time, limit int64) ([]core.FeedSource, error)
+	Advance(ctx context.Context, source core.FeedSource, next, now time.Time) (bool, error)
+	SetLastJob(ctx context.Context, id, jobID primitive.ObjectID) error
+}
+
+type FetchJobCreator interface {
+	Create(ctx context.Context, url string, opts core.FetchOptions) (core.FetchJob, error)
+}
+
+// FeedSourceService manages registered feed sources and schedules their fetches. Due
+// sources are turned into fetch jobs, so scheduled fetches run on the job workers and
+// can be followed like any other job.
+type FeedSourceService struct {
+	repo FeedSourceStorage
+	jobs FetchJobCreator
+	log  logger.Logger
+	cfg  config.SchedulerConfig
+
+	wg sync.WaitGroup
+}
+
+func NewFeedSourceService(repo FeedSourceStorage, jobs FetchJobCreator, log logger.Logger, cfg config.SchedulerConfig) *FeedSourceService {
+	return &FeedSourceService{
+		repo: repo,
+		jobs: jobs,
+		log:  log,
+		cfg:  cfg,
+	}
+}
+
+func (s *FeedSourceService) Create(ctx context.Context, source core.FeedSource) (core.FeedSource, error) {
+	now := time.Now().UTC()
+
+	next, err := core.NextRun(source.Schedule, now)
+	if err != nil {
+		return core.FeedSource{}, err
+	}
+
+	source.NextRunAt = next
+	source.CreatedAt = now
+	source.UpdatedAt = now
+
+	return s.repo.Create(ctx, source)
+}
+
+func (s *FeedSourceService) GetByID(ctx context.Context, id primitive.ObjectID) (core.FeedSource, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *FeedSourceService) GetAll(ctx context.Context, f *filters.Filters) ([]core.FeedSource, error) {
+	return s.repo.GetAll(ctx, f)
+}
+
+func (s *FeedSourceService) GetTotalRecords(ctx context.Context) (int64, error) {
+	return s.repo.GetTotalRecords(ctx)
+}
+
+// Update stores the new settings of a source. The next run is worked out again from
+// the new schedule.
+func (s *FeedSourceService) Update(ctx context.Context, source core.FeedSource) (core.FeedSource, error) {
+	now := time.Now().UTC()
+
+	next, err := core.NextRun(source.Schedule, now)
+	if err != nil {
+		return core.FeedSource{}, err
+	}
+
+	source.NextRunAt = next
+	source.UpdatedAt = now
+
+	return s.repo.Update(ctx, source)
+}
+
+func (s *FeedSourceService) Delete(ctx context.Context, id primitive.ObjectID) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// Start launches the scheduler. It stops once ctx is cancelled; Wait blocks until it
+// has returned.
+func (s *FeedSourceService) Start(ctx context.Context) {
+	s.wg.Add(1)
+
+	go s.schedule(ctx)
+}
+
+func (s *FeedSourceService) Wait() {
+	s.wg.Wait()
+}
+
+func (s *FeedSourceService) schedule(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.runDue(ctx, time.Now().UTC())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *FeedSourceService) runDue(ctx context.Context, now time.Time) {
+	sources, err := s.repo.GetDue(ctx, now, dueSourcesLimit)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.log.Error("get due feed sources error", logger.Error(err))
+		}
+
+		return
+	}
+
+	for _, source := range sources {
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.run(ctx, source, now)
+	}
+}
+
+// run starts a fetch for a due source, unless another instance got to it first. A run
+// missed while no instance was up is made once, not once per missed tick.
+func (s *FeedSourceService) run(ctx context.Context, source core.FeedSource, now time.Time) {
+	next, err := core.NextRun(source.Schedule, now)
+	if err != nil {
+		s.log.Error("invalid feed source schedule", logger.String("source_id", source.ID.Hex()), logger.Error(err))
+
+		return
+	}
+
+	claimed, err := s.repo.Advance(ctx, source, next, now)
+	if err != nil {
+		s.log.Error("advance feed source error", logger.String("source_id", source.ID.Hex()), logger.Error(err))
+
+		return
+	}
+
+	if !claimed {
+		return
+	}
+
+	job, err := s.jobs.Create(ctx, source.URL, source.Options)
+	if err != nil {
+		s.log.Error("create scheduled fetch job error", logger.String("source_id", source.ID.Hex()), logger.Error(err))
+
+		return
+	}
+
+	if err = s.repo.SetLastJob(ctx, source.ID, job.ID); err != nil {
+		s.log.Warn("set feed source last job error", logger.String("source_id", source.ID.Hex()), logger.Error(err))
+	}
+}
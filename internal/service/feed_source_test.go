@@ -0,0 +1,87 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ernur-eskermes/product-store/internal/config"
+	"github.com/ernur-eskermes/product-store/internal/core"
+	"github.com/ernur-eskermes/product-store/internal/service"
+	mock_service "github.com/ernur-eskermes/product-store/internal/service/mocks"
+	"github.com/ernur-eskermes/product-store/pkg/logger"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func mockFeedSourceService(t *testing.T) (*service.FeedSourceService, *mock_service.MockFeedSourceStorage, *mock_service.MockFetchJobCreator) {
+	t.Helper()
+
+	mockCtl := gomock.NewController(t)
+	t.Cleanup(mockCtl.Finish)
+
+	feedSourceRepo := mock_service.NewMockFeedSourceStorage(mockCtl)
+	jobs := mock_service.NewMockFetchJobCreator(mockCtl)
+
+	feedSourceService := service.NewFeedSourceService(feedSourceRepo, jobs, logger.New("error", "test"), config.SchedulerConfig{Interval: time.Hour})
+
+	return feedSourceService, feedSourceRepo, jobs
+}
+
+func TestFeedSource_Create(t *testing.T) {
+	feedSourceService, feedSourceRepo, _ := mockFeedSourceService(t)
+
+	before := time.Now().UTC()
+
+	feedSourceRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, source core.FeedSource) (core.FeedSource, error) {
+		require.Equal(t, "https://some-url.com", source.URL)
+		require.True(t, source.NextRunAt.After(before))
+		require.Zero(t, source.NextRunAt.Minute())
+		require.Zero(t, source.NextRunAt.Second())
+		require.False(t, source.CreatedAt.IsZero())
+
+		return source, nil
+	})
+
+	_, err := feedSourceService.Create(context.Background(), core.FeedSource{URL: "https://some-url.com", Schedule: "0 * * * *"})
+	require.NoError(t, err)
+
+	_, err = feedSourceService.Create(context.Background(), core.FeedSource{URL: "https://some-url.com", Schedule: "every hour"})
+	require.Error(t, err)
+}
+
+func TestFeedSource_Schedule(t *testing.T) {
+	feedSourceService, feedSourceRepo, jobs := mockFeedSourceService(t)
+
+	claimed := core.FeedSource{ID: primitive.NewObjectID(), URL: "https://some-url.com/a.csv", Schedule: "@hourly", Enabled: true}
+	taken := core.FeedSource{ID: primitive.NewObjectID(), URL: "https://some-url.com/b.csv", Schedule: "@hourly", Enabled: true}
+	jobID := primitive.NewObjectID()
+	done := make(chan struct{})
+
+	feedSourceRepo.EXPECT().GetDue(gomock.Any(), gomock.Any(), gomock.Any()).Return([]core.FeedSource{claimed, taken}, nil)
+	feedSourceRepo.EXPECT().Advance(gomock.Any(), claimed, gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ core.FeedSource, next, now time.Time) (bool, error) {
+		require.True(t, next.After(now))
+
+		return true, nil
+	})
+	feedSourceRepo.EXPECT().Advance(gomock.Any(), taken, gomock.Any(), gomock.Any()).Return(false, nil)
+	jobs.EXPECT().Create(gomock.Any(), claimed.URL, claimed.Options).Return(core.FetchJob{ID: jobID}, nil)
+	feedSourceRepo.EXPECT().SetLastJob(gomock.Any(), claimed.ID, jobID).DoAndReturn(func(context.Context, primitive.ObjectID, primitive.ObjectID) error {
+		close(done)
+
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	feedSourceService.Start(ctx)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scheduled fetch job was not created")
+	}
+
+	cancel()
+	feedSourceService.Wait()
+}
@@ -0,0 +1,149 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	mock_service "github.com/ernur-eskermes/product-store/internal/service/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProduct_FetchFormats(t *testing.T) {
+	products := []core.Product{{Name: "Apple", Price: dollars(100)}, {Name: "Pear", Price: dollars(200)}}
+
+	cases := []struct {
+		name        string
+		url         string
+		contentType string
+		format      core.FeedFormat
+		body        string
+		expResp     []core.Product
+		expErr      string
+	}{
+		{
+			name:        "json_by_content_type",
+			url:         "https://some-url.com/feed",
+			contentType: "application/json; charset=utf-8",
+			body:        `[{"name": "Apple", "price": 100}, {"name": "Pear", "price": "200"}]`,
+			expResp:     products,
+		},
+		{
+			name:    "json_object_by_extension",
+			url:     "https://some-url.com/feed.JSON?v=1",
+			body:    `{"updated": "today", "meta": {"count": 2}, "products": [{"name": "Apple", "price": 100}, {"name": "Pear", "price": 200}]}`,
+			expResp: products,
+		},
+		{
+			name:        "ndjson_by_content_type",
+			url:         "https://some-url.com/feed",
+			contentType: "application/x-ndjson",
+			body:        "{\"name\": \"Apple\", \"price\": 100}\n\n{\"name\": \"Pear\", \"price\": 200}\n",
+			expResp:     products,
+		},
+		{
+			name:    "ndjson_by_extension",
+			url:     "https://some-url.com/feed.jsonl",
+			body:    "{\"name\": \"Apple\", \"price\": 100}\n{\"name\": \"Pear\", \"price\": 200}",
+			expResp: products,
+		},
+		{
+			name:        "xml_by_content_type",
+			url:         "https://some-url.com/feed",
+			contentType: "text/xml",
+			body:        `<?xml version="1.0"?><catalog><products><product><name>Apple</name><price>100</price></product><product name="Pear" price="200"/></products></catalog>`,
+			expResp:     products,
+		},
+		{
+			name:        "explicit_format_wins",
+			url:         "https://some-url.com/feed.csv",
+			contentType: "text/csv",
+			format:      core.FeedFormatNDJSON,
+			body:        "{\"name\": \"Apple\", \"price\": 100}\n{\"name\": \"Pear\", \"price\": 200}\n",
+			expResp:     products,
+		},
+		{
+			name:        "csv_by_default",
+			url:         "https://some-url.com/feed",
+			contentType: "text/plain",
+			body:        "PRODUCT NAME;PRICE\nApple;100\nPear;200\n",
+			expResp:     products,
+		},
+		{
+			name:    "names_trimmed_in_json",
+			url:     "https://some-url.com/feed.json",
+			body:    `[{"name": " Apple ", "price": 100}, {"name": "\tPear\n", "price": 200}]`,
+			expResp: products,
+		},
+		{
+			name:    "names_trimmed_in_ndjson",
+			url:     "https://some-url.com/feed.ndjson",
+			body:    "{\"name\": \" Apple \", \"price\": 100}\n{\"name\": \"Pear \", \"price\": 200}\n",
+			expResp: products,
+		},
+		{
+			name:    "names_trimmed_in_csv",
+			url:     "https://some-url.com/feed.csv",
+			body:    "PRODUCT NAME;PRICE\n Apple ;100\nPear  ;200\n",
+			expResp: products,
+		},
+		{
+			name:    "sku_in_xml",
+			url:     "https://some-url.com/feed.xml",
+			body:    `<products><product sku="A-1"><name>Apple</name><price>100</price></product><product><sku>A-2</sku><name>Pear</name><price>200</price></product></products>`,
+			expResp: []core.Product{{SKU: "A-1", Name: "Apple", Price: dollars(100)}, {SKU: "A-2", Name: "Pear", Price: dollars(200)}},
+		},
+		{
+			name:    "sku_in_ndjson",
+			url:     "https://some-url.com/feed.ndjson",
+			body:    "{\"sku\": \"A-1\", \"name\": \"Apple\", \"price\": 100}\n{\"name\": \"Pear\", \"price\": 200}\n",
+			expResp: []core.Product{{SKU: "A-1", Name: "Apple", Price: dollars(100)}, {Name: "Pear", Price: dollars(200)}},
+		},
+		{
+			name:    "invalid_json",
+			url:     "https://some-url.com/feed.json",
+			body:    `{"name": "Apple"}`,
+			expErr:  "json feed has no products array",
+			expResp: []core.Product{},
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			defer mockCtl.Finish()
+
+			httpClient := mock_service.NewMockHTTPClient(mockCtl)
+			productService, productRepo, _ := mockProductService(t, httpClient)
+
+			header := http.Header{}
+			if s.contentType != "" {
+				header.Set("Content-Type", s.contentType)
+			}
+
+			httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{StatusCode: http.StatusOK, Header: header, Body: ioutil.NopCloser(bytes.NewReader([]byte(s.body)))}, nil)
+
+			stored := make([]core.Product, 0)
+			productRepo.EXPECT().UpdateOrCreate(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, products []core.Product, _ interface{}) (core.WriteResult, error) {
+					stored = append(stored, products...)
+
+					return core.WriteResult{Inserted: len(products)}, nil
+				},
+			).AnyTimes()
+
+			_, err := productService.Fetch(context.Background(), s.url, core.FetchOptions{Format: s.format}, nil)
+			if s.expErr != "" {
+				require.EqualError(t, err, s.expErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, s.expResp, stored)
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ernur-eskermes/product-store/internal/config"
+	"github.com/ernur-eskermes/product-store/internal/core"
+	"github.com/ernur-eskermes/product-store/pkg/filters"
+	"github.com/ernur-eskermes/product-store/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// finishTimeout bounds the write that records the outcome of a job. It runs on a fresh
+// context because the job's own context is usually cancelled by then.
+const finishTimeout = 10 * time.Second
+
+type FetchJobStorage interface {
+	Create(ctx context.Context, job core.FetchJob) (core.FetchJob, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (core.FetchJob, error)
+	GetAll(ctx context.Context, filter core.FetchJobFilter, f *filters.Filters) ([]core.FetchJob, error)
+	GetTotalRecords(ctx context.Context, filter core.FetchJobFilter) (int64, error)
+	Claim(ctx context.Context, workerID string, staleBefore time.Time) (core.FetchJob, error)
+	Heartbeat(ctx context.Context, id primitive.ObjectID, workerID string, rowsProcessed int) (core.FetchJob, error)
+	Finish(ctx context.Context, job core.FetchJob) error
+	Release(ctx context.Context, id primitive.ObjectID, workerID string) error
+	Cancel(ctx context.Context, id primitive.ObjectID) (core.FetchJob, error)
+}
+
+type ProductFetcher interface {
+	Fetch(ctx context.Context, url string, opts core.FetchOptions, progress func(rows int)) (core.FetchReport, error)
+}
+
+// FetchJobService runs fetch jobs in a pool of background workers. Jobs are queued in
+// storage, so any instance can accept, report on or cancel a job no matter which
+// instance ends up running it.
+type FetchJobService struct {
+	repo     FetchJobStorage
+	products ProductFetcher
+	log      logger.Logger
+	cfg      config.JobsConfig
+
+	workerID string
+	wake     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewFetchJobService(repo FetchJobStorage, products ProductFetcher, log logger.Logger, cfg config.JobsConfig) *FetchJobService {
+	return &FetchJobService{
+		repo:     repo,
+		products: products,
+		log:      log,
+		cfg:      cfg,
+
+		workerID: primitive.NewObjectID().Hex(),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+func (s *FetchJobService) Create(ctx context.Context, url string, opts core.FetchOptions) (core.FetchJob, error) {
+	job, err := s.repo.Create(ctx, core.FetchJob{
+		URL:       url,
+		Options:   opts,
+		Status:    core.FetchJobPending,
+		Errors:    []string{},
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return core.FetchJob{}, err
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return job, nil
+}
+
+func (s *FetchJobService) GetByID(ctx context.Context, id primitive.ObjectID) (core.FetchJob, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *FetchJobService) GetAll(ctx context.Context, filter core.FetchJobFilter, f *filters.Filters) ([]core.FetchJob, error) {
+	return s.repo.GetAll(ctx, filter, f)
+}
+
+func (s *FetchJobService) GetTotalRecords(ctx context.Context, filter core.FetchJobFilter) (int64, error) {
+	return s.repo.GetTotalRecords(ctx, filter)
+}
+
+func (s *FetchJobService) Cancel(ctx context.Context, id primitive.ObjectID) (core.FetchJob, error) {
+	return s.repo.Cancel(ctx, id)
+}
+
+// Start launches the workers. They stop once ctx is cancelled; Wait blocks until they
+// have returned.
+func (s *FetchJobService) Start(ctx context.Context) {
+	for i := 0; i < s.cfg.Workers; i++ {
+		s.wg.Add(1)
+
+		go s.work(ctx)
+	}
+}
+
+func (s *FetchJobService) Wait() {
+	s.wg.Wait()
+}
+
+func (s *FetchJobService) work(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if s.runNext(ctx) {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *FetchJobService) runNext(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	job, err := s.repo.Claim(ctx, s.workerID, time.Now().UTC().Add(-s.cfg.StaleAfter))
+	if errors.Is(err, core.ErrFetchJobNotFound) {
+		return false
+	}
+
+	if err != nil {
+		s.log.Error("claim fetch job error", logger.Error(err))
+
+		return false
+	}
+
+	s.run(ctx, job)
+
+	return true
+}
+
+func (s *FetchJobService) run(ctx context.Context, job core.FetchJob) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		rows     int64
+		stopped  int32
+		watching = make(chan struct{})
+	)
+
+	go func() {
+		defer close(watching)
+
+		s.watch(jobCtx, job, &rows, func() {
+			atomic.StoreInt32(&stopped, 1)
+			cancel()
+		})
+	}()
+
+	report, err := s.products.Fetch(jobCtx, job.URL, job.Options, func(n int) {
+		atomic.StoreInt64(&rows, int64(n))
+	})
+
+	cancel()
+	<-watching
+
+	if ctx.Err() != nil && atomic.LoadInt32(&stopped) == 0 {
+		s.release(job)
+
+		return
+	}
+
+	job.RowsProcessed = int(atomic.LoadInt64(&rows))
+	job.Report = report
+	job.FinishedAt = time.Now().UTC()
+
+	switch {
+	case atomic.LoadInt32(&stopped) == 1:
+		job.Status = core.FetchJobCancelled
+	case err != nil:
+		job.Status = core.FetchJobFailed
+		job.Errors = append(job.Errors, err.Error())
+	default:
+		job.Status = core.FetchJobSucceeded
+	}
+
+	finishCtx, finishCancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer finishCancel()
+
+	if err = s.repo.Finish(finishCtx, job); err != nil {
+		s.log.Error("finish fetch job error", logger.String("job_id", job.ID.Hex()), logger.Error(err))
+	}
+}
+
+// watch sends heartbeats for a running job and calls stop when the job has been
+// cancelled or taken over by another worker.
+func (s *FetchJobService) watch(ctx context.Context, job core.FetchJob, rows *int64, stop func()) {
+	ticker := time.NewTicker(s.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current, err := s.repo.Heartbeat(ctx, job.ID, s.workerID, int(atomic.LoadInt64(rows)))
+		if errors.Is(err, core.ErrFetchJobNotFound) || (err == nil && current.CancelRequested) {
+			stop()
+
+			return
+		}
+
+		if err != nil && ctx.Err() == nil {
+			s.log.Warn("fetch job heartbeat error", logger.String("job_id", job.ID.Hex()), logger.Error(err))
+		}
+	}
+}
+
+func (s *FetchJobService) release(job core.FetchJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer cancel()
+
+	if err := s.repo.Release(ctx, job.ID, s.workerID); err != nil {
+		s.log.Error("release fetch job error", logger.String("job_id", job.ID.Hex()), logger.Error(err))
+	}
+}
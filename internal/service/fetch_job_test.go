@@ -0,0 +1,153 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ernur-eskermes/product-store/internal/config"
+	"github.com/ernur-eskermes/product-store/internal/core"
+	"github.com/ernur-eskermes/product-store/internal/service"
+	mock_service "github.com/ernur-eskermes/product-store/internal/service/mocks"
+	"github.com/ernur-eskermes/product-store/pkg/logger"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var testJobsConfig = config.JobsConfig{
+	Workers:           1,
+	PollInterval:      time.Hour,
+	HeartbeatInterval: 10 * time.Millisecond,
+	StaleAfter:        time.Minute,
+}
+
+func mockFetchJobService(t *testing.T) (*service.FetchJobService, *mock_service.MockFetchJobStorage, *mock_service.MockProductFetcher) {
+	t.Helper()
+
+	mockCtl := gomock.NewController(t)
+	t.Cleanup(mockCtl.Finish)
+
+	fetchJobRepo := mock_service.NewMockFetchJobStorage(mockCtl)
+	productFetcher := mock_service.NewMockProductFetcher(mockCtl)
+
+	fetchJobService := service.NewFetchJobService(fetchJobRepo, productFetcher, logger.New("error", "test"), testJobsConfig)
+
+	return fetchJobService, fetchJobRepo, productFetcher
+}
+
+func TestFetchJob_Create(t *testing.T) {
+	fetchJobService, fetchJobRepo, _ := mockFetchJobService(t)
+
+	job := core.FetchJob{ID: primitive.NewObjectID(), URL: "https://some-url.com", Status: core.FetchJobPending}
+
+	fetchJobRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, j core.FetchJob) (core.FetchJob, error) {
+		require.Equal(t, "https://some-url.com", j.URL)
+		require.Equal(t, core.FetchJobPending, j.Status)
+		require.False(t, j.CreatedAt.IsZero())
+
+		return job, nil
+	})
+
+	res, err := fetchJobService.Create(context.Background(), "https://some-url.com", core.FetchOptions{})
+	require.NoError(t, err)
+	require.Equal(t, job, res)
+
+	fetchJobRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(core.FetchJob{}, errors.New("error1"))
+
+	_, err = fetchJobService.Create(context.Background(), "https://some-url.com", core.FetchOptions{})
+	require.EqualError(t, err, "error1")
+}
+
+func TestFetchJob_Run(t *testing.T) {
+	report := core.FetchReport{
+		RowsAccepted: 2,
+		RowsRejected: 1,
+		RejectedRows: []core.RowError{{Line: 3, Column: "PRICE", Reason: "must be a decimal number"}},
+	}
+
+	cases := []struct {
+		name         string
+		expStatus    core.FetchJobStatus
+		expRows      int
+		expReport    core.FetchReport
+		expErrors    []string
+		mockBehavior func(r *mock_service.MockFetchJobStorage, p *mock_service.MockProductFetcher)
+	}{
+		{
+			name:      "test_ok",
+			expStatus: core.FetchJobSucceeded,
+			expRows:   2,
+			expReport: report,
+			expErrors: []string{},
+			mockBehavior: func(r *mock_service.MockFetchJobStorage, p *mock_service.MockProductFetcher) {
+				p.EXPECT().Fetch(gomock.Any(), "https://some-url.com", gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ string, _ core.FetchOptions, progress func(int)) (core.FetchReport, error) {
+					progress(1)
+					progress(2)
+
+					return report, nil
+				})
+			},
+		},
+		{
+			name:      "error_when_fetching",
+			expStatus: core.FetchJobFailed,
+			expErrors: []string{"error1"},
+			mockBehavior: func(r *mock_service.MockFetchJobStorage, p *mock_service.MockProductFetcher) {
+				p.EXPECT().Fetch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(core.FetchReport{}, errors.New("error1"))
+			},
+		},
+		{
+			name:      "cancel_requested",
+			expStatus: core.FetchJobCancelled,
+			expErrors: []string{},
+			mockBehavior: func(r *mock_service.MockFetchJobStorage, p *mock_service.MockProductFetcher) {
+				r.EXPECT().Heartbeat(gomock.Any(), gomock.Any(), gomock.Any(), 0).Return(core.FetchJob{CancelRequested: true}, nil)
+				p.EXPECT().Fetch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ string, _ core.FetchOptions, _ func(int)) (core.FetchReport, error) {
+					<-ctx.Done()
+
+					return core.FetchReport{}, ctx.Err()
+				})
+			},
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			fetchJobService, fetchJobRepo, productFetcher := mockFetchJobService(t)
+
+			job := core.FetchJob{ID: primitive.NewObjectID(), URL: "https://some-url.com", Status: core.FetchJobRunning, Errors: []string{}}
+			finished := make(chan core.FetchJob, 1)
+
+			s.mockBehavior(fetchJobRepo, productFetcher)
+			gomock.InOrder(
+				fetchJobRepo.EXPECT().Claim(gomock.Any(), gomock.Any(), gomock.Any()).Return(job, nil),
+				fetchJobRepo.EXPECT().Claim(gomock.Any(), gomock.Any(), gomock.Any()).Return(core.FetchJob{}, core.ErrFetchJobNotFound).AnyTimes(),
+			)
+			fetchJobRepo.EXPECT().Heartbeat(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(job, nil).AnyTimes()
+			fetchJobRepo.EXPECT().Finish(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, j core.FetchJob) error {
+				finished <- j
+
+				return nil
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			fetchJobService.Start(ctx)
+
+			select {
+			case j := <-finished:
+				require.Equal(t, s.expStatus, j.Status)
+				require.Equal(t, s.expRows, j.RowsProcessed)
+				require.Equal(t, s.expReport, j.Report)
+				require.Equal(t, s.expErrors, j.Errors)
+				require.False(t, j.FinishedAt.IsZero())
+			case <-time.After(5 * time.Second):
+				t.Fatal("fetch job was not finished")
+			}
+
+			cancel()
+			fetchJobService.Wait()
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+)
+
+// maxNDJSONLine bounds a single line of an NDJSON feed.
+const maxNDJSONLine = 1 << 20
+
+// jsonProduct is a product as it appears in JSON and NDJSON feeds. Prices may be given
+// either as numbers or as numeric strings.
+type jsonProduct struct {
+	SKU      string          `json:"sku"`
+	Name     string          `json:"name"`
+	Price    json.RawMessage `json:"price"`
+	Currency string          `json:"currency"`
+}
+
+func (p jsonProduct) product(line int, currency string) (core.Product, error) {
+	price := string(p.Price)
+	if price == "null" {
+		price = ""
+	}
+
+	if strings.HasPrefix(price, `"`) {
+		if err := json.Unmarshal(p.Price, &price); err != nil {
+			return core.Product{}, &core.RowError{Line: line, Column: core.ProductFieldPrice, Reason: errNotADecimal.Error()}
+		}
+	}
+
+	res := core.Product{SKU: strings.TrimSpace(p.SKU), Name: strings.TrimSpace(p.Name)}
+
+	var (
+		field string
+		err   error
+	)
+
+	if res.Price, field, err = parsePrice(price, p.Currency, currency); err != nil {
+		return core.Product{}, &core.RowError{Line: line, Column: field, Reason: err.Error()}
+	}
+
+	return res, nil
+}
+
+// jsonRowError turns a value of the wrong type into a row error. Any other error means
+// the document itself is broken.
+func jsonRowError(line int, err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return &core.RowError{Line: line, Column: typeErr.Field, Reason: fmt.Sprintf("unexpected %s value", typeErr.Value)}
+	}
+
+	return err
+}
+
+// jsonDecoder reads products from a JSON feed: either an array of products or an object
+// holding that array under "products". Elements are decoded one at a time, so the whole
+// document is never held in memory. Rows are numbered by their position in the array.
+// Prices are in currency unless a product gives its own.
+type jsonDecoder struct {
+	d        *json.Decoder
+	currency string
+	started  bool
+	n        int
+}
+
+func newJSONDecoder(in io.Reader, currency string) *jsonDecoder {
+	return &jsonDecoder{d: json.NewDecoder(in), currency: currency}
+}
+
+// Decode returns the next product in the feed, or io.EOF once the feed is exhausted.
+func (d *jsonDecoder) Decode() (core.Product, error) {
+	if !d.started {
+		if err := d.start(); err != nil {
+			return core.Product{}, err
+		}
+
+		d.started = true
+	}
+
+	if !d.d.More() {
+		return core.Product{}, io.EOF
+	}
+
+	d.n++
+
+	var p jsonProduct
+	if err := d.d.Decode(&p); err != nil {
+		return core.Product{}, jsonRowError(d.n, err)
+	}
+
+	return p.product(d.n, d.currency)
+}
+
+func (d *jsonDecoder) Line() int {
+	return d.n
+}
+
+func (d *jsonDecoder) Column(field string) string {
+	return field
+}
+
+// start moves the decoder to the first element of the products array.
+func (d *jsonDecoder) start() error {
+	tok, err := d.d.Token()
+	if errors.Is(err, io.EOF) {
+		return io.EOF
+	}
+
+	if err != nil {
+		return err
+	}
+
+	switch tok {
+	case json.Delim('['):
+		return nil
+	case json.Delim('{'):
+	default:
+		return errors.New("json feed must be an array or an object with a products array")
+	}
+
+	for d.d.More() {
+		key, err := d.d.Token()
+		if err != nil {
+			return err
+		}
+
+		if name, _ := key.(string); strings.EqualFold(name, "products") {
+			tok, err = d.d.Token()
+			if err != nil {
+				return err
+			}
+
+			if tok != json.Delim('[') {
+				return errors.New("json feed products must be an array")
+			}
+
+			return nil
+		}
+
+		var skip json.RawMessage
+		if err = d.d.Decode(&skip); err != nil {
+			return err
+		}
+	}
+
+	return errors.New("json feed has no products array")
+}
+
+// ndjsonDecoder reads products from a feed holding one JSON object per line. A line
+// that is not valid JSON only rejects that row. Prices are in currency unless a product
+// gives its own.
+type ndjsonDecoder struct {
+	s        *bufio.Scanner
+	currency string
+	line     int
+}
+
+func newNDJSONDecoder(in io.Reader, currency string) *ndjsonDecoder {
+	s := bufio.NewScanner(in)
+	s.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+
+	return &ndjsonDecoder{s: s, currency: currency}
+}
+
+// Decode returns the next product in the feed, or io.EOF once the feed is exhausted.
+func (d *ndjsonDecoder) Decode() (core.Product, error) {
+	for d.s.Scan() {
+		d.line++
+
+		line := bytes.TrimSpace(d.s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var p jsonProduct
+		if err := json.Unmarshal(line, &p); err != nil {
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				return core.Product{}, &core.RowError{Line: d.line, Reason: "invalid json: " + err.Error()}
+			}
+
+			return core.Product{}, jsonRowError(d.line, err)
+		}
+
+		return p.product(d.line, d.currency)
+	}
+
+	if err := d.s.Err(); err != nil {
+		return core.Product{}, err
+	}
+
+	return core.Product{}, io.EOF
+}
+
+func (d *ndjsonDecoder) Line() int {
+	return d.line
+}
+
+func (d *ndjsonDecoder) Column(field string) string {
+	return field
+}
@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/drop_dir.go
+
+// Package mock_service is a generated GoMock package.
+package mock_service
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	core "github.com/ernur-eskermes/product-store/internal/core"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockFeedUploader is a mock of FeedUploader interface.
+type MockFeedUploader struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedUploaderMockRecorder
+}
+
+// MockFeedUploaderMockRecorder is the mock recorder for MockFeedUploader.
+type MockFeedUploaderMockRecorder struct {
+	mock *MockFeedUploader
+}
+
+// NewMockFeedUploader creates a new mock instance.
+func NewMockFeedUploader(ctrl *gomock.Controller) *MockFeedUploader {
+	mock := &MockFeedUploader{ctrl: ctrl}
+	mock.recorder = &MockFeedUploaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeedUploader) EXPECT() *MockFeedUploaderMockRecorder {
+	return m.recorder
+}
+
+// Upload mocks base method.
+func (m *MockFeedUploader) Upload(ctx context.Context, name string, r io.Reader, opts core.FetchOptions) (core.FetchReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upload", ctx, name, r, opts)
+	ret0, _ := ret[0].(core.FetchReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upload indicates an expected call of Upload.
+func (mr *MockFeedUploaderMockRecorder) Upload(ctx, name, r, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upload", reflect.TypeOf((*MockFeedUploader)(nil).Upload), ctx, name, r, opts)
+}
@@ -12,6 +12,7 @@ import (
 	core "github.com/ernur-eskermes/product-store/internal/core"
 	filters "github.com/ernur-eskermes/product-store/pkg/filters"
 	gomock "github.com/golang/mock/gomock"
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // MockProductStorage is a mock of ProductStorage interface.
@@ -37,75 +38,314 @@ func (m *MockProductStorage) EXPECT() *MockProductStorageMockRecorder {
 	return m.recorder
 }
 
+// Discontinue mocks base method.
+func (m *MockProductStorage) Discontinue(ctx context.Context, run core.FetchRun) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Discontinue", ctx, run)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Discontinue indicates an expected call of Discontinue.
+func (mr *MockProductStorageMockRecorder) Discontinue(ctx, run interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Discontinue", reflect.TypeOf((*MockProductStorage)(nil).Discontinue), ctx, run)
+}
+
 // GetAll mocks base method.
-func (m *MockProductStorage) GetAll(ctx context.Context, f *filters.Filters) ([]core.Product, error) {
+func (m *MockProductStorage) GetAll(ctx context.Context, filter core.ProductFilter, f *filters.Filters, after *core.ProductCursor) ([]core.Product, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", ctx, f)
+	ret := m.ctrl.Call(m, "GetAll", ctx, filter, f, after)
 	ret0, _ := ret[0].([]core.Product)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockProductStorageMockRecorder) GetAll(ctx, f interface{}) *gomock.Call {
+func (mr *MockProductStorageMockRecorder) GetAll(ctx, filter, f, after interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockProductStorage)(nil).GetAll), ctx, filter, f, after)
+}
+
+// GetByID mocks base method.
+func (m *MockProductStorage) GetByID(ctx context.Context, id primitive.ObjectID) (core.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(core.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockProductStorageMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockProductStorage)(nil).GetByID), ctx, id)
+}
+
+// GetBySKU mocks base method.
+func (m *MockProductStorage) GetBySKU(ctx context.Context, sku string) (core.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBySKU", ctx, sku)
+	ret0, _ := ret[0].(core.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBySKU indicates an expected call of GetBySKU.
+func (mr *MockProductStorageMockRecorder) GetBySKU(ctx, sku interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBySKU", reflect.TypeOf((*MockProductStorage)(nil).GetBySKU), ctx, sku)
+}
+
+// GetPrices mocks base method.
+func (m *MockProductStorage) GetPrices(ctx context.Context, sourceURL string, products []core.Product) (map[core.ProductKey]core.Money, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrices", ctx, sourceURL, products)
+	ret0, _ := ret[0].(map[core.ProductKey]core.Money)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrices indicates an expected call of GetPrices.
+func (mr *MockProductStorageMockRecorder) GetPrices(ctx, sourceURL, products interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrices", reflect.TypeOf((*MockProductStorage)(nil).GetPrices), ctx, sourceURL, products)
+}
+
+// GetSearchTotalRecords mocks base method.
+func (m *MockProductStorage) GetSearchTotalRecords(ctx context.Context, query string, filter core.ProductFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSearchTotalRecords", ctx, query, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSearchTotalRecords indicates an expected call of GetSearchTotalRecords.
+func (mr *MockProductStorageMockRecorder) GetSearchTotalRecords(ctx, query, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockProductStorage)(nil).GetAll), ctx, f)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSearchTotalRecords", reflect.TypeOf((*MockProductStorage)(nil).GetSearchTotalRecords), ctx, query, filter)
 }
 
 // GetTotalRecords mocks base method.
-func (m *MockProductStorage) GetTotalRecords(ctx context.Context) (int64, error) {
+func (m *MockProductStorage) GetTotalRecords(ctx context.Context, filter core.ProductFilter) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTotalRecords", ctx)
+	ret := m.ctrl.Call(m, "GetTotalRecords", ctx, filter)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetTotalRecords indicates an expected call of GetTotalRecords.
-func (mr *MockProductStorageMockRecorder) GetTotalRecords(ctx interface{}) *gomock.Call {
+func (mr *MockProductStorageMockRecorder) GetTotalRecords(ctx, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotalRecords", reflect.TypeOf((*MockProductStorage)(nil).GetTotalRecords), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotalRecords", reflect.TypeOf((*MockProductStorage)(nil).GetTotalRecords), ctx, filter)
 }
 
-// UpdateOrCreate mocks base method.
-func (m *MockProductStorage) UpdateOrCreate(ctx context.Context, products []core.Product) error {
+// Scan mocks base method.
+func (m *MockProductStorage) Scan(ctx context.Context, sourceURL string, fn func(core.Product) bool) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateOrCreate", ctx, products)
+	ret := m.ctrl.Call(m, "Scan", ctx, sourceURL, fn)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// Scan indicates an expected call of Scan.
+func (mr *MockProductStorageMockRecorder) Scan(ctx, sourceURL, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scan", reflect.TypeOf((*MockProductStorage)(nil).Scan), ctx, sourceURL, fn)
+}
+
+// Search mocks base method.
+func (m *MockProductStorage) Search(ctx context.Context, query string, filter core.ProductFilter, f *filters.Filters) ([]core.SearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, query, filter, f)
+	ret0, _ := ret[0].([]core.SearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockProductStorageMockRecorder) Search(ctx, query, filter, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockProductStorage)(nil).Search), ctx, query, filter, f)
+}
+
+// Suggest mocks base method.
+func (m *MockProductStorage) Suggest(ctx context.Context, prefix string, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Suggest", ctx, prefix, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Suggest indicates an expected call of Suggest.
+func (mr *MockProductStorageMockRecorder) Suggest(ctx, prefix, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Suggest", reflect.TypeOf((*MockProductStorage)(nil).Suggest), ctx, prefix, limit)
+}
+
+// UpdateOrCreate mocks base method.
+func (m *MockProductStorage) UpdateOrCreate(ctx context.Context, products []core.Product, run core.FetchRun) (core.WriteResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrCreate", ctx, products, run)
+	ret0, _ := ret[0].(core.WriteResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // UpdateOrCreate indicates an expected call of UpdateOrCreate.
-func (mr *MockProductStorageMockRecorder) UpdateOrCreate(ctx, products interface{}) *gomock.Call {
+func (mr *MockProductStorageMockRecorder) UpdateOrCreate(ctx, products, run interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrCreate", reflect.TypeOf((*MockProductStorage)(nil).UpdateOrCreate), ctx, products, run)
+}
+
+// MockPriceHistoryStorage is a mock of PriceHistoryStorage interface.
+type MockPriceHistoryStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockPriceHistoryStorageMockRecorder
+}
+
+// MockPriceHistoryStorageMockRecorder is the mock recorder for MockPriceHistoryStorage.
+type MockPriceHistoryStorageMockRecorder struct {
+	mock *MockPriceHistoryStorage
+}
+
+// NewMockPriceHistoryStorage creates a new mock instance.
+func NewMockPriceHistoryStorage(ctrl *gomock.Controller) *MockPriceHistoryStorage {
+	mock := &MockPriceHistoryStorage{ctrl: ctrl}
+	mock.recorder = &MockPriceHistoryStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPriceHistoryStorage) EXPECT() *MockPriceHistoryStorageMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockPriceHistoryStorage) Create(ctx context.Context, changes []core.PriceChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, changes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockPriceHistoryStorageMockRecorder) Create(ctx, changes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPriceHistoryStorage)(nil).Create), ctx, changes)
+}
+
+// GetAll mocks base method.
+func (m *MockPriceHistoryStorage) GetAll(ctx context.Context, filter core.PriceHistoryFilter, f *filters.Filters) ([]core.PriceChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, filter, f)
+	ret0, _ := ret[0].([]core.PriceChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockPriceHistoryStorageMockRecorder) GetAll(ctx, filter, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockPriceHistoryStorage)(nil).GetAll), ctx, filter, f)
+}
+
+// GetTotalRecords mocks base method.
+func (m *MockPriceHistoryStorage) GetTotalRecords(ctx context.Context, filter core.PriceHistoryFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTotalRecords", ctx, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTotalRecords indicates an expected call of GetTotalRecords.
+func (mr *MockPriceHistoryStorageMockRecorder) GetTotalRecords(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotalRecords", reflect.TypeOf((*MockPriceHistoryStorage)(nil).GetTotalRecords), ctx, filter)
+}
+
+// MockFeedCacheStorage is a mock of FeedCacheStorage interface.
+type MockFeedCacheStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedCacheStorageMockRecorder
+}
+
+// MockFeedCacheStorageMockRecorder is the mock recorder for MockFeedCacheStorage.
+type MockFeedCacheStorageMockRecorder struct {
+	mock *MockFeedCacheStorage
+}
+
+// NewMockFeedCacheStorage creates a new mock instance.
+func NewMockFeedCacheStorage(ctrl *gomock.Controller) *MockFeedCacheStorage {
+	mock := &MockFeedCacheStorage{ctrl: ctrl}
+	mock.recorder = &MockFeedCacheStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeedCacheStorage) EXPECT() *MockFeedCacheStorageMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockFeedCacheStorage) Get(ctx context.Context, url string) (core.FeedCache, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, url)
+	ret0, _ := ret[0].(core.FeedCache)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockFeedCacheStorageMockRecorder) Get(ctx, url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockFeedCacheStorage)(nil).Get), ctx, url)
+}
+
+// Save mocks base method.
+func (m *MockFeedCacheStorage) Save(ctx context.Context, cache core.FeedCache) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, cache)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockFeedCacheStorageMockRecorder) Save(ctx, cache interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrCreate", reflect.TypeOf((*MockProductStorage)(nil).UpdateOrCreate), ctx, products)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockFeedCacheStorage)(nil).Save), ctx, cache)
 }
 
-// MockHttpClient is a mock of HttpClient interface.
-type MockHttpClient struct {
+// MockHTTPClient is a mock of HTTPClient interface.
+type MockHTTPClient struct {
 	ctrl     *gomock.Controller
-	recorder *MockHttpClientMockRecorder
+	recorder *MockHTTPClientMockRecorder
 }
 
-// MockHttpClientMockRecorder is the mock recorder for MockHttpClient.
-type MockHttpClientMockRecorder struct {
-	mock *MockHttpClient
+// MockHTTPClientMockRecorder is the mock recorder for MockHTTPClient.
+type MockHTTPClientMockRecorder struct {
+	mock *MockHTTPClient
 }
 
-// NewMockHttpClient creates a new mock instance.
-func NewMockHttpClient(ctrl *gomock.Controller) *MockHttpClient {
-	mock := &MockHttpClient{ctrl: ctrl}
-	mock.recorder = &MockHttpClientMockRecorder{mock}
+// NewMockHTTPClient creates a new mock instance.
+func NewMockHTTPClient(ctrl *gomock.Controller) *MockHTTPClient {
+	mock := &MockHTTPClient{ctrl: ctrl}
+	mock.recorder = &MockHTTPClientMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockHttpClient) EXPECT() *MockHttpClientMockRecorder {
+func (m *MockHTTPClient) EXPECT() *MockHTTPClientMockRecorder {
 	return m.recorder
 }
 
 // Do mocks base method.
-func (m *MockHttpClient) Do(req *http.Request) (*http.Response, error) {
+func (m *MockHTTPClient) Do(req *http.Request) (*http.Response, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Do", req)
 	ret0, _ := ret[0].(*http.Response)
@@ -114,7 +354,7 @@ func (m *MockHttpClient) Do(req *http.Request) (*http.Response, error) {
 }
 
 // Do indicates an expected call of Do.
-func (mr *MockHttpClientMockRecorder) Do(req interface{}) *gomock.Call {
+func (mr *MockHTTPClientMockRecorder) Do(req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Do", reflect.TypeOf((*MockHttpClient)(nil).Do), req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Do", reflect.TypeOf((*MockHTTPClient)(nil).Do), req)
 }
@@ -0,0 +1,128 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	mock_service "github.com/ernur-eskermes/product-store/internal/service/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProduct_FetchPrices(t *testing.T) {
+	cases := []struct {
+		name        string
+		url         string
+		body        string
+		opts        core.FetchOptions
+		expResp     []core.Product
+		expRejected []core.RowError
+	}{
+		{
+			name: "decimal_prices",
+			url:  "https://some-url.com/feed.csv",
+			body: "PRODUCT NAME;PRICE\nApple;12.99\nPear;0,5\nPlum;3\n",
+			expResp: []core.Product{
+				{Name: "Apple", Price: core.Money{Amount: 1299, Currency: "USD"}},
+				{Name: "Pear", Price: core.Money{Amount: 50, Currency: "USD"}},
+				{Name: "Plum", Price: core.Money{Amount: 300, Currency: "USD"}},
+			},
+		},
+		{
+			name: "currency_column",
+			url:  "https://some-url.com/feed.csv",
+			body: "PRODUCT NAME;PRICE;CURRENCY\nApple;12.99;eur\nPear;1299;JPY\nPlum;1.234;KWD\nKiwi;2;\n",
+			expResp: []core.Product{
+				{Name: "Apple", Price: core.Money{Amount: 1299, Currency: "EUR"}},
+				{Name: "Pear", Price: core.Money{Amount: 1299, Currency: "JPY"}},
+				{Name: "Plum", Price: core.Money{Amount: 1234, Currency: "KWD"}},
+				{Name: "Kiwi", Price: core.Money{Amount: 200, Currency: "USD"}},
+			},
+		},
+		{
+			name: "requested_currency",
+			url:  "https://some-url.com/feed.csv",
+			body: "PRODUCT NAME;PRICE\nApple;1299\n",
+			opts: core.FetchOptions{Currency: "JPY"},
+			expResp: []core.Product{
+				{Name: "Apple", Price: core.Money{Amount: 1299, Currency: "JPY"}},
+			},
+		},
+		{
+			name: "invalid_prices",
+			url:  "https://some-url.com/feed.csv",
+			body: "PRODUCT NAME;PRICE;CURRENCY\nApple;12.999;USD\nPear;12.5;JPY\nPlum;12.;USD\nLime;1;USD\n",
+			expResp: []core.Product{
+				{Name: "Lime", Price: core.Money{Amount: 100, Currency: "USD"}},
+			},
+			expRejected: []core.RowError{
+				{Line: 2, Column: "PRICE", Reason: "must have at most 2 decimal places in USD"},
+				{Line: 3, Column: "PRICE", Reason: "must be a whole number in JPY"},
+				{Line: 4, Column: "PRICE", Reason: "must be a decimal number"},
+			},
+		},
+		{
+			name:    "invalid_currency",
+			url:     "https://some-url.com/feed.csv",
+			body:    "PRODUCT NAME;PRICE;CURRENCY\nKiwi;12;US\nFig;99999999999999999999;USD\nPlum;1;ZZZ\n",
+			expResp: []core.Product{},
+			expRejected: []core.RowError{
+				{Line: 2, Column: "CURRENCY", Reason: "must be a three-letter ISO 4217 currency code"},
+				{Line: 3, Column: "PRICE", Reason: "is out of range"},
+				{Line: 4, Column: "CURRENCY", Reason: "must be a three-letter ISO 4217 currency code"},
+			},
+		},
+		{
+			name: "json",
+			url:  "https://some-url.com/feed.json",
+			body: `[{"name": "Apple", "price": 12.99, "currency": "EUR"}, {"name": "Pear", "price": "0.5"}]`,
+			expResp: []core.Product{
+				{Name: "Apple", Price: core.Money{Amount: 1299, Currency: "EUR"}},
+				{Name: "Pear", Price: core.Money{Amount: 50, Currency: "USD"}},
+			},
+		},
+		{
+			name: "xml",
+			url:  "https://some-url.com/feed.xml",
+			body: `<products><product currency="GBP"><name>Apple</name><price>12.99</price></product><product name="Pear" price="0.5"><currency>EUR</currency></product></products>`,
+			expResp: []core.Product{
+				{Name: "Apple", Price: core.Money{Amount: 1299, Currency: "GBP"}},
+				{Name: "Pear", Price: core.Money{Amount: 50, Currency: "EUR"}},
+			},
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			defer mockCtl.Finish()
+
+			httpClient := mock_service.NewMockHTTPClient(mockCtl)
+			productService, productRepo, _ := mockProductService(t, httpClient)
+
+			httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte(s.body)))}, nil)
+
+			stored := make([]core.Product, 0)
+			productRepo.EXPECT().UpdateOrCreate(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, products []core.Product, _ core.FetchRun) (core.WriteResult, error) {
+					stored = append(stored, products...)
+
+					return core.WriteResult{Inserted: len(products)}, nil
+				},
+			).AnyTimes()
+
+			report, err := productService.Fetch(context.Background(), s.url, s.opts, nil)
+			require.NoError(t, err)
+
+			require.Equal(t, s.expResp, stored)
+			require.Equal(t, len(s.expRejected), report.RowsRejected)
+			if len(s.expRejected) != 0 {
+				require.Equal(t, s.expRejected, report.RejectedRows)
+			}
+		})
+	}
+}
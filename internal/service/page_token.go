@@ -0,0 +1,51 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// pageTokens turns product cursors into opaque page tokens and back. A token is the
+// cursor followed by its HMAC, so tokens that were not issued with key are refused.
+type pageTokens struct {
+	key []byte
+}
+
+func (t pageTokens) encode(c core.ProductCursor) (string, error) {
+	b, err := bson.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(append(b, t.sign(b)...)), nil
+}
+
+func (t pageTokens) decode(token string) (core.ProductCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(b) < sha256.Size {
+		return core.ProductCursor{}, core.ErrInvalidPageToken
+	}
+
+	payload, mac := b[:len(b)-sha256.Size], b[len(b)-sha256.Size:]
+	if !hmac.Equal(mac, t.sign(payload)) {
+		return core.ProductCursor{}, core.ErrInvalidPageToken
+	}
+
+	var c core.ProductCursor
+	if err = bson.Unmarshal(payload, &c); err != nil {
+		return core.ProductCursor{}, core.ErrInvalidPageToken
+	}
+
+	return c, nil
+}
+
+func (t pageTokens) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, t.key)
+	mac.Write(payload)
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,125 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	"github.com/ernur-eskermes/product-store/internal/service"
+	mock_service "github.com/ernur-eskermes/product-store/internal/service/mocks"
+	"github.com/ernur-eskermes/product-store/pkg/filters"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestProduct_GetAllPageToken(t *testing.T) {
+	ctx := context.Background()
+
+	products := []core.Product{
+		{ID: primitive.NewObjectID(), SKU: "A-1", Name: "Apple", Price: dollars(1), UpdatedAt: time.Date(2022, 7, 1, 10, 0, 0, 0, time.UTC)},
+		{ID: primitive.NewObjectID(), Name: "Pear", Price: dollars(2), UpdatedAt: time.Date(2022, 7, 2, 10, 0, 0, 0, time.UTC)},
+	}
+
+	sorts := append([]string{"-price,name", "sku,-updated_at,name"}, core.ProductSortSafeList...)
+
+	for _, sort := range sorts {
+		t.Run(sort, func(t *testing.T) {
+			productService, productRepo, _ := mockProductService(t, nil)
+			f := filters.New(1, 2, sort, core.ProductDefaultSort, core.ProductSortSafeList)
+
+			productRepo.EXPECT().GetAll(gomock.Any(), core.ProductFilter{}, f, nil).Return(products, nil)
+
+			_, token, err := productService.GetAll(ctx, core.ProductFilter{}, f, "")
+			require.NoError(t, err)
+			require.NotEmpty(t, token)
+
+			expCursor := core.NewProductCursor(products[1], sort)
+
+			productRepo.EXPECT().GetAll(gomock.Any(), core.ProductFilter{}, f, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ core.ProductFilter, _ *filters.Filters, after *core.ProductCursor) ([]core.Product, error) {
+					require.NotNil(t, after)
+					require.Equal(t, expCursor.Sort, after.Sort)
+					require.Equal(t, expCursor.ID, after.ID)
+					require.Len(t, after.Key, len(expCursor.Key))
+
+					return products[:1], nil
+				},
+			)
+
+			_, token, err = productService.GetAll(ctx, core.ProductFilter{}, f, token)
+			require.NoError(t, err)
+			require.Empty(t, token)
+		})
+	}
+}
+
+func TestProduct_GetAllInvalidPageToken(t *testing.T) {
+	ctx := context.Background()
+
+	products := []core.Product{{ID: primitive.NewObjectID(), Name: "Apple", Price: dollars(1)}}
+
+	productService, productRepo, _ := mockProductService(t, nil)
+	byName := filters.New(1, 1, "name", core.ProductDefaultSort, core.ProductSortSafeList)
+
+	productRepo.EXPECT().GetAll(gomock.Any(), gomock.Any(), gomock.Any(), nil).Return(products, nil)
+
+	_, token, err := productService.GetAll(ctx, core.ProductFilter{}, byName, "")
+	require.NoError(t, err)
+
+	cases := []struct {
+		name  string
+		token string
+		f     *filters.Filters
+	}{
+		{
+			name:  "not_base64",
+			token: "not a token",
+			f:     byName,
+		},
+		{
+			name:  "tampered",
+			token: tamper(token),
+			f:     byName,
+		},
+		{
+			name:  "truncated",
+			token: token[:8],
+			f:     byName,
+		},
+		{
+			name:  "other_sort",
+			token: token,
+			f:     filters.New(1, 1, "-name", core.ProductDefaultSort, core.ProductSortSafeList),
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			_, _, err := productService.GetAll(ctx, core.ProductFilter{}, s.f, s.token)
+			require.True(t, errors.Is(err, core.ErrInvalidPageToken))
+		})
+	}
+
+	t.Run("other_key", func(t *testing.T) {
+		mockCtl := gomock.NewController(t)
+		defer mockCtl.Finish()
+
+		otherRepo := mock_service.NewMockProductStorage(mockCtl)
+		other := service.NewProductService(otherRepo, mock_service.NewMockPriceHistoryStorage(mockCtl), mock_service.NewMockFeedCacheStorage(mockCtl), nil, testFetchConfig, "another key")
+
+		_, _, err := other.GetAll(ctx, core.ProductFilter{}, byName, token)
+		require.True(t, errors.Is(err, core.ErrInvalidPageToken))
+	})
+}
+
+// tamper changes the first character of token.
+func tamper(token string) string {
+	if token[0] == 'A' {
+		return "B" + token[1:]
+	}
+
+	return "A" + token[1:]
+}
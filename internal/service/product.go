@@ -1,18 +1,46 @@
 package service
 
 import (
+	"archive/zip"
+	"bufio"
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
+	"github.com/ernur-eskermes/product-store/internal/config"
 	"github.com/ernur-eskermes/product-store/internal/core"
 	"github.com/ernur-eskermes/product-store/pkg/filters"
-	"github.com/gocarina/gocsv"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type ProductStorage interface {
-	GetAll(ctx context.Context, f *filters.Filters) ([]core.Product, error)
-	UpdateOrCreate(ctx context.Context, products []core.Product) error
-	GetTotalRecords(ctx context.Context) (int64, error)
+	GetAll(ctx context.Context, filter core.ProductFilter, f *filters.Filters, after *core.ProductCursor) ([]core.Product, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (core.Product, error)
+	GetBySKU(ctx context.Context, sku string) (core.Product, error)
+	UpdateOrCreate(ctx context.Context, products []core.Product, run core.FetchRun) (core.WriteResult, error)
+	Discontinue(ctx context.Context, run core.FetchRun) (int, error)
+	GetTotalRecords(ctx context.Context, filter core.ProductFilter) (int64, error)
+	Search(ctx context.Context, query string, filter core.ProductFilter, f *filters.Filters) ([]core.SearchResult, error)
+	GetSearchTotalRecords(ctx context.Context, query string, filter core.ProductFilter) (int64, error)
+	Suggest(ctx context.Context, prefix string, limit int) ([]string, error)
+	GetPrices(ctx context.Context, sourceURL string, products []core.Product) (map[core.ProductKey]core.Money, error)
+	Scan(ctx context.Context, sourceURL string, fn func(core.Product) bool) error
+}
+
+type PriceHistoryStorage interface {
+	Create(ctx context.Context, changes []core.PriceChange) error
+	GetAll(ctx context.Context, filter core.PriceHistoryFilter, f *filters.Filters) ([]core.PriceChange, error)
+	GetTotalRecords(ctx context.Context, filter core.PriceHistoryFilter) (int64, error)
+}
+
+type FeedCacheStorage interface {
+	Get(ctx context.Context, url string) (core.FeedCache, error)
+	Save(ctx context.Context, cache core.FeedCache) error
 }
 
 type HTTPClient interface {
@@ -20,47 +48,457 @@ type HTTPClient interface {
 }
 
 type ProductService struct {
-	repo ProductStorage
+	repo             ProductStorage
+	priceHistoryRepo PriceHistoryStorage
+	feedCacheRepo    FeedCacheStorage
+
+	openers map[string]FeedOpener
+	tokens  pageTokens
+	cfg     config.FetchConfig
+}
+
+// NewProductService returns a service fetching http and https feeds with httpClient
+// and data URLs. file URLs are opened only when cfg sets FileRoot, and only for files
+// under it. Page tokens are signed with pageTokenKey, which must be the same for every
+// instance and across restarts for tokens to stay valid.
+func NewProductService(repo ProductStorage, priceHistoryRepo PriceHistoryStorage, feedCacheRepo FeedCacheStorage, httpClient HTTPClient, cfg config.FetchConfig, pageTokenKey string) *ProductService {
+	web := &httpOpener{client: httpClient, cfg: cfg}
+
+	s := &ProductService{
+		repo:             repo,
+		priceHistoryRepo: priceHistoryRepo,
+		feedCacheRepo:    feedCacheRepo,
+
+		openers: map[string]FeedOpener{
+			"http":  web,
+			"https": web,
+			"data":  dataOpener{},
+		},
+		tokens: pageTokens{key: []byte(pageTokenKey)},
+		cfg:    cfg,
+	}
+
+	if cfg.FileRoot != "" {
+		s.RegisterOpener("file", &fileOpener{root: cfg.FileRoot})
+	}
+
+	return s
+}
+
+// GetAll returns a page of the products filter lets through: the one after pageToken
+// if it is set, and the page of f otherwise. A full page comes with the token of the
+// page after it, which is empty once the products run out. A token only holds for the
+// sort it was issued for.
+func (s *ProductService) GetAll(ctx context.Context, filter core.ProductFilter, f *filters.Filters, pageToken string) ([]core.Product, string, error) {
+	var after *core.ProductCursor
+
+	if pageToken != "" {
+		cursor, err := s.tokens.decode(pageToken)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if cursor.Sort != f.Sort {
+			return nil, "", fmt.Errorf("%w: it was issued for sort %q", core.ErrInvalidPageToken, cursor.Sort)
+		}
+
+		after = &cursor
+	}
+
+	products, err := s.repo.GetAll(ctx, filter, f, after)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if int64(len(products)) < f.Limit() || len(products) == 0 {
+		return products, "", nil
+	}
+
+	next, err := s.tokens.encode(core.NewProductCursor(products[len(products)-1], f.Sort))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return products, next, nil
+}
+
+func (s *ProductService) GetTotalRecords(ctx context.Context, filter core.ProductFilter) (int64, error) {
+	return s.repo.GetTotalRecords(ctx, filter)
+}
+
+func (s *ProductService) GetByID(ctx context.Context, id primitive.ObjectID) (core.Product, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *ProductService) GetBySKU(ctx context.Context, sku string) (core.Product, error) {
+	return s.repo.GetBySKU(ctx, sku)
+}
+
+// UpdateOrCreate saves the products fetched by run and records every price change it
+// causes in the price history.
+func (s *ProductService) UpdateOrCreate(ctx context.Context, run core.FetchRun, products []core.Product) (core.WriteResult, error) {
+	res, err := s.repo.UpdateOrCreate(ctx, products, run)
+	if err != nil {
+		return core.WriteResult{}, err
+	}
+
+	if len(res.Changes) == 0 {
+		return res, nil
+	}
+
+	for i := range res.Changes {
+		res.Changes[i].SourceURL = run.SourceURL
+	}
 
-	httpClient HTTPClient
+	if err = s.priceHistoryRepo.Create(ctx, res.Changes); err != nil {
+		return core.WriteResult{}, err
+	}
+
+	return res, nil
 }
 
-func NewProductService(repo ProductStorage, httpClient HTTPClient) *ProductService {
-	return &ProductService{
-		repo: repo,
+func (s *ProductService) GetPriceHistory(ctx context.Context, filter core.PriceHistoryFilter, f *filters.Filters) ([]core.PriceChange, error) {
+	return s.priceHistoryRepo.GetAll(ctx, filter, f)
+}
+
+func (s *ProductService) GetPriceHistoryTotalRecords(ctx context.Context, filter core.PriceHistoryFilter) (int64, error) {
+	return s.priceHistoryRepo.GetTotalRecords(ctx, filter)
+}
+
+// ingestion holds the state of one fetch, which may span several files of an archive.
+type ingestion struct {
+	run       core.FetchRun
+	dryRun    bool
+	validator *rowValidator
+	report    core.FetchReport
+	progress  func(rows int)
+}
+
+// countingReader adds the number of bytes read through it to n.
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	*c.n += int64(n)
+
+	return n, err
+}
+
+// Fetch streams the feed at url, opened by the FeedOpener of its scheme, into storage
+// in batches of the configured size, so memory use does not depend on the size of the
+// feed. Compressed feeds are decompressed on the fly, and every file of a zip archive
+// is ingested in turn. The feed is opened knowing what was cached about the last
+// successful fetch, and a feed that is not modified, or whose body is identical to
+// the last one, is not ingested again; the report says why in Skipped.
+// Rows that fail validation are skipped and listed in the returned report, which is
+// also returned, covering the rows read so far, when the fetch fails. A dry run stores
+// nothing and reports how the feed differs from the catalog instead. A snapshot then
+// discontinues the products of the source that the feed no longer has. progress, if
+// set, is called after every stored batch with the number of rows stored so far.
+func (s *ProductService) Fetch(ctx context.Context, url string, opts core.FetchOptions, progress func(rows int)) (core.FetchReport, error) {
+	return s.run(ctx, url, opts, progress, func(in *ingestion) error {
+		return s.fetch(ctx, in, url, opts)
+	})
+}
+
+// Upload ingests the feed read from r, the way Fetch ingests a downloaded one, except
+// that nothing is cached. name is the file name of the feed, used to detect its
+// compression and format; it is recorded as the source of price changes with an
+// "upload:" prefix. Only zip archives are copied to disk before they are read.
+func (s *ProductService) Upload(ctx context.Context, name string, r io.Reader, opts core.FetchOptions) (core.FetchReport, error) {
+	return s.run(ctx, "upload:"+name, opts, nil, func(in *ingestion) error {
+		return s.ingestFeed(ctx, in, &countingReader{r: r, n: &in.report.BytesDownloaded}, "", "", name, opts)
+	})
+}
+
+// run ingests a feed from sourceURL with ingest and reports on it.
+func (s *ProductService) run(ctx context.Context, sourceURL string, opts core.FetchOptions, progress func(rows int), ingest func(in *ingestion) error) (core.FetchReport, error) {
+	start := time.Now()
+
+	in := &ingestion{
+		run:       core.FetchRun{ID: primitive.NewObjectID(), SourceURL: sourceURL, StartedAt: start.UTC()},
+		dryRun:    opts.DryRun,
+		validator: newRowValidator(s.cfg.MaxTrackedKeys),
+		report:    core.FetchReport{RejectedRows: []core.RowError{}},
+		progress:  progress,
+	}
+
+	if in.dryRun {
+		in.report.Diff = newCatalogDiff()
+	}
+
+	err := ingest(in)
+	in.report.DuplicatesUnchecked = in.validator.untracked
+
+	if err == nil && in.dryRun {
+		err = s.diffMissing(ctx, in)
+	}
+
+	if err == nil && opts.Snapshot {
+		err = s.discontinue(ctx, in)
+	}
+
+	in.report.Duration = time.Since(start)
+
+	return in.report, err
+}
+
+func (s *ProductService) fetch(ctx context.Context, in *ingestion, rawURL string, opts core.FetchOptions) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return &core.FeedError{Err: err}
+	}
+
+	// Dry runs always read the feed, and do not count as a fetch of it. Data URLs carry
+	// the feed itself, so there is nothing worth caching about them.
+	cached := !in.dryRun && !strings.EqualFold(u.Scheme, "data")
+
+	var cache core.FeedCache
+	if cached {
+		if cache, err = s.cacheFor(ctx, rawURL, opts); err != nil {
+			return err
+		}
+	}
+
+	feed, err := s.open(ctx, FeedRequest{URL: u, Auth: opts.Auth, Cache: cache})
+	if err != nil {
+		return err
+	}
+
+	if feed.NotModified {
+		in.report.Skipped = core.FetchSkipNotModified
+
+		return nil
+	}
+	defer feed.Body.Close()
+
+	// Feeds downloaded over HTTP are spooled as they are downloaded, so that a body
+	// cut off midway can be downloaded again.
+	spooled, cleanup, err := s.spoolFeed(in, feed.Body)
+	if err != nil {
+		return &core.DownloadError{Err: err}
+	}
+	defer cleanup()
+
+	fetched := core.FeedCache{
+		URL:          rawURL,
+		Options:      cacheOptions(opts),
+		ETag:         feed.ETag,
+		LastModified: feed.LastModified,
+		ContentHash:  spooled.Hash,
+		FetchedAt:    time.Now().UTC(),
+	}
+
+	if cached && cache.ContentHash == spooled.Hash {
+		in.report.Skipped = core.FetchSkipSameContent
+
+		return s.feedCacheRepo.Save(ctx, fetched)
+	}
+
+	if err = s.ingestFeed(ctx, in, spooled, feed.ContentEncoding, feed.ContentType, rawURL, opts); err != nil {
+		return err
+	}
 
-		httpClient: httpClient,
+	if !cached {
+		return nil
 	}
+
+	return s.feedCacheRepo.Save(ctx, fetched)
 }
 
-func (s *ProductService) GetAll(ctx context.Context, f *filters.Filters) ([]core.Product, error) {
-	return s.repo.GetAll(ctx, f)
+// spoolFeed returns body spooled to disk, counting the bytes downloaded, and a cleanup
+// removing the spool.
+func (s *ProductService) spoolFeed(in *ingestion, body io.ReadCloser) (*spooledFeed, func(), error) {
+	if spooled, ok := body.(*spooledBody); ok {
+		in.report.BytesDownloaded += spooled.Size
+
+		return spooled.spooledFeed, spooled.cleanup, nil
+	}
+
+	return spool(&countingReader{r: body, n: &in.report.BytesDownloaded})
 }
 
-func (s *ProductService) GetTotalRecords(ctx context.Context) (int64, error) {
-	return s.repo.GetTotalRecords(ctx)
+// ingestFeed ingests the feed read from r, named name, decompressing it first if
+// needed. A zip archive is spooled to disk unless r already is.
+func (s *ProductService) ingestFeed(ctx context.Context, in *ingestion, r io.Reader, contentEncoding, contentType, name string, opts core.FetchOptions) error {
+	body := bufio.NewReader(r)
+
+	c := detectCompression(body, contentEncoding, name)
+	if c == compressionZip {
+		feed, ok := r.(*spooledFeed)
+		if !ok {
+			spooled, cleanup, err := spool(body)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			feed = spooled
+		}
+
+		return s.ingestZip(ctx, in, feed, opts)
+	}
+
+	dr, err := decompressStream(body, c)
+	if err != nil {
+		return &core.FeedError{Err: err}
+	}
+
+	opts.Format = detectFeedFormat(opts.Format, contentType, name)
+
+	return s.ingest(ctx, in, "", dr, opts)
 }
 
-func (s *ProductService) UpdateOrCreate(ctx context.Context, products []core.Product) error {
-	return s.repo.UpdateOrCreate(ctx, products)
+func (s *ProductService) ingestZip(ctx context.Context, in *ingestion, feed *spooledFeed, opts core.FetchOptions) error {
+	archive, err := zip.NewReader(feed, feed.Size)
+	if err != nil {
+		return &core.FeedError{Err: err}
+	}
+
+	for _, f := range archive.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		entryOpts := opts
+		entryOpts.Format = detectFeedFormat(opts.Format, "", f.Name)
+
+		if err = s.ingestZipEntry(ctx, in, f, entryOpts); err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+	}
+
+	return nil
 }
 
-func (s *ProductService) GetCSVProducts(ctx context.Context, url string) ([]core.Product, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+func (s *ProductService) ingestZipEntry(ctx context.Context, in *ingestion, f *zip.File, opts core.FetchOptions) error {
+	r, err := f.Open()
 	if err != nil {
-		return nil, err
+		return &core.FeedError{Err: err}
+	}
+	defer r.Close()
+
+	return s.ingest(ctx, in, f.Name, r, opts)
+}
+
+// ingest validates and stores the products read from r, the whole feed or the archive
+// entry named file. Prices without a currency are in opts.Currency, or in the
+// configured default currency.
+func (s *ProductService) ingest(ctx context.Context, in *ingestion, file string, r io.Reader, opts core.FetchOptions) error {
+	if opts.Currency == "" {
+		opts.Currency = s.cfg.DefaultCurrency
 	}
 
-	resp, err := s.httpClient.Do(req)
+	decoder, err := newFeedDecoder(r, opts.Format, opts)
 	if err != nil {
-		return nil, err
+		return &core.FeedError{Err: err}
 	}
-	defer resp.Body.Close()
 
-	products := make([]core.Product, 0)
-	if err = gocsv.Unmarshal(resp.Body, &products); err != nil {
-		return nil, err
+	batch := make([]core.Product, 0, s.cfg.BatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if in.dryRun {
+			if err := s.diff(ctx, in.run, in.report.Diff, batch); err != nil {
+				return err
+			}
+		} else {
+			res, err := s.UpdateOrCreate(ctx, in.run, batch)
+			if err != nil {
+				return err
+			}
+
+			in.report.ProductsInserted += res.Inserted
+			in.report.ProductsRepriced += res.Repriced
+			in.report.ProductsUnchanged += res.Unchanged
+		}
+
+		in.report.RowsAccepted += len(batch)
+		batch = make([]core.Product, 0, s.cfg.BatchSize)
+
+		if in.progress != nil {
+			in.progress(in.report.RowsAccepted)
+		}
+
+		return nil
 	}
 
-	return products, nil
+	for {
+		product, err := decoder.Decode()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err == nil || errors.As(err, new(*core.RowError)) {
+			in.report.RowsRead++
+		}
+
+		var rowErr *core.RowError
+
+		switch {
+		case errors.As(err, &rowErr):
+			rowErr.File = file
+			s.reject(&in.report, *rowErr)
+
+			continue
+		case err != nil:
+			return &core.FeedError{Err: err}
+		}
+
+		if rowErr = in.validator.validate(file, decoder, product); rowErr != nil {
+			s.reject(&in.report, *rowErr)
+
+			continue
+		}
+
+		batch = append(batch, product)
+
+		if len(batch) >= s.cfg.BatchSize {
+			if err = flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return flush()
+}
+
+// discontinue marks the products of the source that the run did not see as
+// discontinued. Nothing is discontinued unless the feed was ingested and had products,
+// so that an unchanged feed, a dry run or an emptied feed leaves the catalog alone. A
+// feed with rejected rows does not tell which products it lost either, so nothing is
+// discontinued and the report says so.
+func (s *ProductService) discontinue(ctx context.Context, in *ingestion) error {
+	if in.dryRun || in.report.Skipped != "" || in.report.RowsAccepted == 0 {
+		return nil
+	}
+
+	if in.report.RowsRejected > 0 {
+		in.report.DiscontinueSkipped = true
+
+		return nil
+	}
+
+	n, err := s.repo.Discontinue(ctx, in.run)
+	if err != nil {
+		return err
+	}
+
+	in.report.ProductsDiscontinued = n
+
+	return nil
+}
+
+func (s *ProductService) reject(report *core.FetchReport, e core.RowError) {
+	report.RowsRejected++
+
+	if len(report.RejectedRows) < s.cfg.MaxRejectedRows {
+		report.RejectedRows = append(report.RejectedRows, e)
+	}
 }
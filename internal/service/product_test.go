@@ -7,69 +7,150 @@ import (
 	"io/ioutil"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/ernur-eskermes/product-store/internal/config"
 	"github.com/ernur-eskermes/product-store/internal/core"
 	"github.com/ernur-eskermes/product-store/internal/service"
 	mock_service "github.com/ernur-eskermes/product-store/internal/service/mocks"
 	"github.com/ernur-eskermes/product-store/pkg/filters"
-	"github.com/gocarina/gocsv"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func mockProductService(t *testing.T, httpClient service.HTTPClient) (*service.ProductService, *mock_service.MockProductStorage) {
+const testPageTokenKey = "test key"
+
+var testFetchConfig = config.FetchConfig{
+	BatchSize:       2,
+	MaxRejectedRows: 3,
+	DiffSampleSize:  1,
+	MaxTrackedKeys:  100,
+	MaxRetries:      2,
+	RetryBaseDelay:  time.Millisecond,
+	RetryMaxDelay:   5 * time.Millisecond,
+	DefaultCurrency: "USD",
+}
+
+// dollars returns a price of n whole US dollars, as a feed giving n is read.
+func dollars(n int64) core.Money {
+	return core.Money{Amount: n * 100, Currency: "USD"}
+}
+
+func mockProductService(t *testing.T, httpClient service.HTTPClient) (*service.ProductService, *mock_service.MockProductStorage, *mock_service.MockPriceHistoryStorage) {
+	t.Helper()
+
+	return mockProductServiceWithConfig(t, httpClient, testFetchConfig)
+}
+
+func mockProductServiceWithConfig(t *testing.T, httpClient service.HTTPClient, cfg config.FetchConfig) (*service.ProductService, *mock_service.MockProductStorage, *mock_service.MockPriceHistoryStorage) {
 	t.Helper()
 
 	mockCtl := gomock.NewController(t)
 	defer mockCtl.Finish()
 
 	productRepo := mock_service.NewMockProductStorage(mockCtl)
+	priceHistoryRepo := mock_service.NewMockPriceHistoryStorage(mockCtl)
 
-	productService := service.NewProductService(productRepo, httpClient)
+	// Nothing is cached, so every fetch ingests the feed.
+	feedCacheRepo := mock_service.NewMockFeedCacheStorage(mockCtl)
+	feedCacheRepo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(core.FeedCache{}, nil).AnyTimes()
+	feedCacheRepo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-	return productService, productRepo
+	productService := service.NewProductService(productRepo, priceHistoryRepo, feedCacheRepo, httpClient, cfg, testPageTokenKey)
+
+	return productService, productRepo, priceHistoryRepo
 }
 
-func TestProduct_GetCSVProducts(t *testing.T) {
-	type mockBehavior func(r *mock_service.MockHttpClient)
+func TestProduct_Fetch(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage)
 
 	mockCtl := gomock.NewController(t)
 	defer mockCtl.Finish()
 
-	httpClient := mock_service.NewMockHttpClient(mockCtl)
-	productService, _ := mockProductService(t, httpClient)
+	httpClient := mock_service.NewMockHTTPClient(mockCtl)
+	productService, productRepo, _ := mockProductService(t, httpClient)
 
 	ctx := context.Background()
 
 	products := []core.Product{
-		{ID: primitive.ObjectID{}, Name: "Test Product", Price: 1000},
-		{ID: primitive.ObjectID{}, Name: "Test Product2", Price: 2538},
+		{ID: primitive.ObjectID{}, Name: "Test Product", Price: dollars(1000)},
+		{ID: primitive.ObjectID{}, Name: "Test Product2", Price: dollars(2538)},
+		{ID: primitive.ObjectID{}, Name: "Test Product3", Price: dollars(12)},
 	}
-	b, err := gocsv.MarshalBytes(products)
-	require.NoError(t, err)
+	b := []byte("PRODUCT NAME;PRICE\nTest Product;1000\nTest Product2;2538\nTest Product3;12\n")
 
 	cases := []struct {
 		name         string
 		url          string
-		expResp      []core.Product
+		expProgress  []int
+		expReport    *core.FetchReport
 		expErr       string
 		mockBehavior mockBehavior
 	}{
 		{
-			name:    "test_ok",
-			url:     "https://some-url.com",
-			expResp: products,
-			mockBehavior: func(r *mock_service.MockHttpClient) {
+			name:        "test_ok",
+			url:         "https://some-url.com",
+			expProgress: []int{2, 3},
+			expReport: &core.FetchReport{
+				RowsRead:          3,
+				RowsAccepted:      3,
+				RejectedRows:      []core.RowError{},
+				ProductsInserted:  1,
+				ProductsRepriced:  1,
+				ProductsUnchanged: 1,
+				BytesDownloaded:   int64(len(b)),
+			},
+			mockBehavior: func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage) {
 				httpResp := ioutil.NopCloser(bytes.NewReader(b))
-				r.EXPECT().Do(gomock.Any()).Return(&http.Response{Body: httpResp}, nil)
+				r.EXPECT().Do(gomock.Any()).Return(&http.Response{StatusCode: http.StatusOK, Body: httpResp}, nil)
+				gomock.InOrder(
+					p.EXPECT().UpdateOrCreate(gomock.Any(), products[:2], gomock.Any()).Return(core.WriteResult{Inserted: 1, Unchanged: 1}, nil),
+					p.EXPECT().UpdateOrCreate(gomock.Any(), products[2:], gomock.Any()).Return(core.WriteResult{Repriced: 1}, nil),
+				)
+			},
+		},
+		{
+			name:        "invalid_price",
+			url:         "https://some-url.com",
+			expProgress: []int{1},
+			expReport: &core.FetchReport{
+				RowsRead:          2,
+				RowsAccepted:      1,
+				RowsRejected:      1,
+				RejectedRows:      []core.RowError{{Line: 3, Column: "PRICE", Reason: "must be a decimal number"}},
+				ProductsUnchanged: 1,
+				BytesDownloaded:   55,
+			},
+			mockBehavior: func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage) {
+				httpResp := ioutil.NopCloser(bytes.NewReader([]byte("PRODUCT NAME;PRICE\nTest Product;1000\nTest Product2;abc\n")))
+				r.EXPECT().Do(gomock.Any()).Return(&http.Response{StatusCode: http.StatusOK, Body: httpResp}, nil)
+				p.EXPECT().UpdateOrCreate(gomock.Any(), products[:1], gomock.Any()).Return(core.WriteResult{Unchanged: 1}, nil)
+			},
+		},
+		{
+			name:        "malformed_row",
+			url:         "https://some-url.com",
+			expProgress: []int{2},
+			expReport: &core.FetchReport{
+				RowsRead:         3,
+				RowsAccepted:     2,
+				RowsRejected:     1,
+				RejectedRows:     []core.RowError{{Line: 3, Reason: `bare " in non-quoted-field`}},
+				ProductsInserted: 2,
+				BytesDownloaded:  74,
+			},
+			mockBehavior: func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage) {
+				httpResp := ioutil.NopCloser(bytes.NewReader([]byte("PRODUCT NAME;PRICE\nTest Product;1000\nTest \"Product2;2538\nTest Product3;12\n")))
+				r.EXPECT().Do(gomock.Any()).Return(&http.Response{StatusCode: http.StatusOK, Body: httpResp}, nil)
+				p.EXPECT().UpdateOrCreate(gomock.Any(), []core.Product{products[0], products[2]}, gomock.Any()).Return(core.WriteResult{Inserted: 2}, nil)
 			},
 		},
 		{
 			name:   "error_when_requesting",
 			url:    "https://some-url.com",
 			expErr: "error1",
-			mockBehavior: func(r *mock_service.MockHttpClient) {
+			mockBehavior: func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage) {
 				r.EXPECT().Do(gomock.Any()).Return(nil, errors.New("error1"))
 			},
 		},
@@ -77,28 +158,53 @@ func TestProduct_GetCSVProducts(t *testing.T) {
 			name:   "empty_byte_given",
 			url:    "https://some-url.com",
 			expErr: "empty csv file given",
-			mockBehavior: func(r *mock_service.MockHttpClient) {
+			mockBehavior: func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage) {
 				httpResp := ioutil.NopCloser(bytes.NewReader([]byte("")))
-				r.EXPECT().Do(gomock.Any()).Return(&http.Response{Body: httpResp}, nil)
+				r.EXPECT().Do(gomock.Any()).Return(&http.Response{StatusCode: http.StatusOK, Body: httpResp}, nil)
 			},
 		},
 		{
 			name:         "empty_url",
 			url:          "://some-url.com",
 			expErr:       "parse \"://some-url.com\": missing protocol scheme",
-			mockBehavior: func(r *mock_service.MockHttpClient) {},
+			mockBehavior: func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage) {},
+		},
+		{
+			name:        "error_when_storing_batch",
+			url:         "https://some-url.com",
+			expErr:      "error2",
+			expProgress: []int{},
+			mockBehavior: func(r *mock_service.MockHTTPClient, p *mock_service.MockProductStorage) {
+				httpResp := ioutil.NopCloser(bytes.NewReader(b))
+				r.EXPECT().Do(gomock.Any()).Return(&http.Response{StatusCode: http.StatusOK, Body: httpResp}, nil)
+				p.EXPECT().UpdateOrCreate(gomock.Any(), products[:2], gomock.Any()).Return(core.WriteResult{}, errors.New("error2"))
+			},
 		},
 	}
 
 	for _, s := range cases {
 		t.Run(s.name, func(t *testing.T) {
-			s.mockBehavior(httpClient)
+			s.mockBehavior(httpClient, productRepo)
 
-			p, err := productService.GetCSVProducts(ctx, s.url)
-			if err != nil {
+			progress := make([]int, 0)
+
+			report, err := productService.Fetch(ctx, s.url, core.FetchOptions{}, func(rows int) {
+				progress = append(progress, rows)
+			})
+			if s.expErr != "" {
 				require.EqualError(t, err, s.expErr)
 			} else {
-				require.Equal(t, p, s.expResp)
+				require.NoError(t, err)
+			}
+
+			if s.expReport != nil {
+				require.Positive(t, report.Duration)
+				report.Duration = 0
+				require.Equal(t, *s.expReport, report)
+			}
+
+			if s.expProgress != nil {
+				require.Equal(t, s.expProgress, progress)
 			}
 		})
 	}
@@ -107,11 +213,11 @@ func TestProduct_GetCSVProducts(t *testing.T) {
 func TestProduct_GetAll(t *testing.T) {
 	type mockBehavior func(r *mock_service.MockProductStorage)
 
-	productService, productRepo := mockProductService(t, nil)
+	productService, productRepo, _ := mockProductService(t, nil)
 
 	ctx := context.Background()
 
-	products := []core.Product{{ID: primitive.ObjectID{}, Name: "Test Product", Price: 1000}}
+	products := []core.Product{{ID: primitive.ObjectID{}, Name: "Test Product", Price: dollars(1000)}}
 
 	cases := []struct {
 		name         string
@@ -123,14 +229,14 @@ func TestProduct_GetAll(t *testing.T) {
 			name:    "test_ok",
 			expResp: products,
 			mockBehavior: func(r *mock_service.MockProductStorage) {
-				r.EXPECT().GetAll(gomock.Any(), gomock.Any()).Return(products, nil)
+				r.EXPECT().GetAll(gomock.Any(), core.ProductFilter{IncludeDiscontinued: true}, gomock.Any(), nil).Return(products, nil)
 			},
 		},
 		{
 			name:   "error_when_calling_GetAll",
 			expErr: "error1",
 			mockBehavior: func(r *mock_service.MockProductStorage) {
-				r.EXPECT().GetAll(gomock.Any(), gomock.Any()).Return(nil, errors.New("error1"))
+				r.EXPECT().GetAll(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("error1"))
 			},
 		},
 	}
@@ -139,11 +245,12 @@ func TestProduct_GetAll(t *testing.T) {
 		t.Run(s.name, func(t *testing.T) {
 			s.mockBehavior(productRepo)
 
-			p, err := productService.GetAll(ctx, &filters.Filters{})
+			p, next, err := productService.GetAll(ctx, core.ProductFilter{IncludeDiscontinued: true}, filters.New(1, 30, "", core.ProductDefaultSort, core.ProductSortSafeList), "")
 			if err != nil {
 				require.EqualError(t, err, s.expErr)
 			} else {
 				require.Equal(t, p, s.expResp)
+				require.Empty(t, next)
 			}
 		})
 	}
@@ -152,7 +259,7 @@ func TestProduct_GetAll(t *testing.T) {
 func TestProduct_GetTotalRecords(t *testing.T) {
 	type mockBehavior func(r *mock_service.MockProductStorage)
 
-	productService, productRepo := mockProductService(t, nil)
+	productService, productRepo, _ := mockProductService(t, nil)
 
 	ctx := context.Background()
 
@@ -166,14 +273,14 @@ func TestProduct_GetTotalRecords(t *testing.T) {
 			name:    "test_ok",
 			expResp: 12,
 			mockBehavior: func(r *mock_service.MockProductStorage) {
-				r.EXPECT().GetTotalRecords(ctx).Return(int64(12), nil)
+				r.EXPECT().GetTotalRecords(ctx, core.ProductFilter{}).Return(int64(12), nil)
 			},
 		},
 		{
 			name:   "error_when_calling_GetTotalRecords",
 			expErr: "error1",
 			mockBehavior: func(r *mock_service.MockProductStorage) {
-				r.EXPECT().GetTotalRecords(gomock.Any()).Return(int64(0), errors.New("error1"))
+				r.EXPECT().GetTotalRecords(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("error1"))
 			},
 		},
 	}
@@ -182,7 +289,7 @@ func TestProduct_GetTotalRecords(t *testing.T) {
 		t.Run(s.name, func(t *testing.T) {
 			s.mockBehavior(productRepo)
 
-			totalRecords, err := productService.GetTotalRecords(ctx)
+			totalRecords, err := productService.GetTotalRecords(ctx, core.ProductFilter{})
 			if err != nil {
 				require.EqualError(t, err, s.expErr)
 			} else {
@@ -192,31 +299,45 @@ func TestProduct_GetTotalRecords(t *testing.T) {
 	}
 }
 
-func TestProduct_UpdateOrCreate(t *testing.T) {
+func TestProduct_GetProduct(t *testing.T) {
 	type mockBehavior func(r *mock_service.MockProductStorage)
 
-	productService, productRepo := mockProductService(t, nil)
+	productService, productRepo, _ := mockProductService(t, nil)
 
 	ctx := context.Background()
 
-	products := []core.Product{{ID: primitive.ObjectID{}, Name: "Test Product", Price: 1000}}
+	product := core.Product{ID: primitive.NewObjectID(), SKU: "A-1", Name: "Test Product", Price: dollars(1000)}
 
 	cases := []struct {
 		name         string
-		expErr       string
+		id           primitive.ObjectID
+		sku          string
+		expErr       error
+		expResp      core.Product
 		mockBehavior mockBehavior
 	}{
 		{
-			name: "test_ok",
+			name:    "by_id",
+			id:      product.ID,
+			expResp: product,
 			mockBehavior: func(r *mock_service.MockProductStorage) {
-				r.EXPECT().UpdateOrCreate(gomock.Any(), gomock.Any()).Return(nil)
+				r.EXPECT().GetByID(gomock.Any(), product.ID).Return(product, nil)
 			},
 		},
 		{
-			name:   "error_when_calling_UpdateOrCreate",
-			expErr: "error1",
+			name:    "by_sku",
+			sku:     "A-1",
+			expResp: product,
 			mockBehavior: func(r *mock_service.MockProductStorage) {
-				r.EXPECT().UpdateOrCreate(gomock.Any(), gomock.Any()).Return(errors.New("error1"))
+				r.EXPECT().GetBySKU(gomock.Any(), "A-1").Return(product, nil)
+			},
+		},
+		{
+			name:   "not_found",
+			sku:    "A-2",
+			expErr: core.ErrProductNotFound,
+			mockBehavior: func(r *mock_service.MockProductStorage) {
+				r.EXPECT().GetBySKU(gomock.Any(), "A-2").Return(core.Product{}, core.ErrProductNotFound)
 			},
 		},
 	}
@@ -225,8 +346,138 @@ func TestProduct_UpdateOrCreate(t *testing.T) {
 		t.Run(s.name, func(t *testing.T) {
 			s.mockBehavior(productRepo)
 
-			if err := productService.UpdateOrCreate(ctx, products); err != nil {
+			var (
+				p   core.Product
+				err error
+			)
+
+			if s.sku != "" {
+				p, err = productService.GetBySKU(ctx, s.sku)
+			} else {
+				p, err = productService.GetByID(ctx, s.id)
+			}
+
+			if s.expErr != nil {
+				require.ErrorIs(t, err, s.expErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, s.expResp, p)
+		})
+	}
+}
+
+func TestProduct_UpdateOrCreate(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockProductStorage, h *mock_service.MockPriceHistoryStorage)
+
+	productService, productRepo, priceHistoryRepo := mockProductService(t, nil)
+
+	ctx := context.Background()
+
+	products := []core.Product{{ID: primitive.ObjectID{}, Name: "Test Product", Price: dollars(1000)}}
+	changes := []core.PriceChange{{ProductName: "Test Product", OldPrice: dollars(900), NewPrice: dollars(1000)}}
+
+	cases := []struct {
+		name         string
+		expResp      core.WriteResult
+		expErr       string
+		mockBehavior mockBehavior
+	}{
+		{
+			name:    "test_ok",
+			expResp: core.WriteResult{Inserted: 1},
+			mockBehavior: func(r *mock_service.MockProductStorage, h *mock_service.MockPriceHistoryStorage) {
+				r.EXPECT().UpdateOrCreate(gomock.Any(), gomock.Any(), gomock.Any()).Return(core.WriteResult{Inserted: 1}, nil)
+			},
+		},
+		{
+			name: "price_changes_are_recorded",
+			expResp: core.WriteResult{
+				Repriced: 1,
+				Changes:  []core.PriceChange{{ProductName: "Test Product", OldPrice: dollars(900), NewPrice: dollars(1000), SourceURL: "https://some-url.com"}},
+			},
+			mockBehavior: func(r *mock_service.MockProductStorage, h *mock_service.MockPriceHistoryStorage) {
+				r.EXPECT().UpdateOrCreate(gomock.Any(), products, gomock.Any()).Return(core.WriteResult{Repriced: 1, Changes: changes}, nil)
+				h.EXPECT().Create(gomock.Any(), []core.PriceChange{
+					{ProductName: "Test Product", OldPrice: dollars(900), NewPrice: dollars(1000), SourceURL: "https://some-url.com"},
+				}).Return(nil)
+			},
+		},
+		{
+			name:   "error_when_calling_UpdateOrCreate",
+			expErr: "error1",
+			mockBehavior: func(r *mock_service.MockProductStorage, h *mock_service.MockPriceHistoryStorage) {
+				r.EXPECT().UpdateOrCreate(gomock.Any(), gomock.Any(), gomock.Any()).Return(core.WriteResult{}, errors.New("error1"))
+			},
+		},
+		{
+			name:   "error_when_recording_price_history",
+			expErr: "error2",
+			mockBehavior: func(r *mock_service.MockProductStorage, h *mock_service.MockPriceHistoryStorage) {
+				r.EXPECT().UpdateOrCreate(gomock.Any(), gomock.Any(), gomock.Any()).Return(core.WriteResult{Repriced: 1, Changes: []core.PriceChange{{ProductName: "Test Product"}}}, nil)
+				h.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("error2"))
+			},
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			s.mockBehavior(productRepo, priceHistoryRepo)
+
+			res, err := productService.UpdateOrCreate(ctx, core.FetchRun{SourceURL: "https://some-url.com"}, products)
+			if s.expErr != "" {
+				require.EqualError(t, err, s.expErr)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, s.expResp, res)
+			}
+		})
+	}
+}
+
+func TestProduct_GetPriceHistory(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockPriceHistoryStorage)
+
+	productService, _, priceHistoryRepo := mockProductService(t, nil)
+
+	ctx := context.Background()
+
+	filter := core.PriceHistoryFilter{ProductName: "Test Product"}
+	changes := []core.PriceChange{{ProductName: "Test Product", OldPrice: dollars(900), NewPrice: dollars(1000)}}
+
+	cases := []struct {
+		name         string
+		expErr       string
+		expResp      []core.PriceChange
+		mockBehavior mockBehavior
+	}{
+		{
+			name:    "test_ok",
+			expResp: changes,
+			mockBehavior: func(r *mock_service.MockPriceHistoryStorage) {
+				r.EXPECT().GetAll(gomock.Any(), filter, gomock.Any()).Return(changes, nil)
+			},
+		},
+		{
+			name:   "error_when_calling_GetAll",
+			expErr: "error1",
+			mockBehavior: func(r *mock_service.MockPriceHistoryStorage) {
+				r.EXPECT().GetAll(gomock.Any(), filter, gomock.Any()).Return(nil, errors.New("error1"))
+			},
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			s.mockBehavior(priceHistoryRepo)
+
+			c, err := productService.GetPriceHistory(ctx, filter, &filters.Filters{})
+			if err != nil {
 				require.EqualError(t, err, s.expErr)
+			} else {
+				require.Equal(t, c, s.expResp)
 			}
 		})
 	}
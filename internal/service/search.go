@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	"github.com/ernur-eskermes/product-store/pkg/filters"
+)
+
+// minStemLength is the shortest word that highlights the words it is a prefix of, so
+// that "apple" highlights "apples" as the text index matches it.
+const minStemLength = 3
+
+// Search returns a page of the products filter lets through that match the free-text
+// query, most relevant first, with the words of their names and SKUs that match it
+// highlighted.
+func (s *ProductService) Search(ctx context.Context, query string, filter core.ProductFilter, f *filters.Filters) ([]core.SearchResult, error) {
+	results, err := s.repo.Search(ctx, query, filter, f)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := searchTerms(query)
+	for i := range results {
+		results[i].Highlights = highlight(results[i].Product, terms)
+	}
+
+	return results, nil
+}
+
+func (s *ProductService) GetSearchTotalRecords(ctx context.Context, query string, filter core.ProductFilter) (int64, error) {
+	return s.repo.GetSearchTotalRecords(ctx, query, filter)
+}
+
+// Suggest returns up to limit distinct names of products that are not discontinued and
+// start with prefix, ignoring case, accents and repeated spaces.
+func (s *ProductService) Suggest(ctx context.Context, prefix string, limit int) ([]string, error) {
+	return s.repo.Suggest(ctx, core.NameKey(prefix), limit)
+}
+
+// searchTerms returns the lowercased words of query that products are searched for.
+// Words negated with a leading "-" are left out, as products cannot match them.
+func searchTerms(query string) []string {
+	var terms []string
+
+	for _, field := range strings.Fields(strings.ReplaceAll(query, `"`, " ")) {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+
+		for _, w := range words(field) {
+			terms = append(terms, strings.ToLower(field[w.start:w.end]))
+		}
+	}
+
+	return terms
+}
+
+// highlight returns the words of the name and SKU of p that match one of terms.
+func highlight(p core.Product, terms []string) []core.Highlight {
+	highlights := make([]core.Highlight, 0)
+
+	for _, field := range []struct{ name, value string }{{core.ProductFieldName, p.Name}, {core.ProductFieldSKU, p.SKU}} {
+		for _, w := range words(field.value) {
+			if matchesTerm(strings.ToLower(field.value[w.start:w.end]), terms) {
+				highlights = append(highlights, core.Highlight{
+					Field: field.name,
+					Start: len([]rune(field.value[:w.start])),
+					End:   len([]rune(field.value[:w.end])),
+				})
+			}
+		}
+	}
+
+	return highlights
+}
+
+// matchesTerm reports whether word is one of terms, or shares a stem with one: the
+// text index stems words, so "apples" matches "apple". Stems are approximated by
+// prefixes of at least minStemLength characters.
+func matchesTerm(word string, terms []string) bool {
+	for _, term := range terms {
+		short, long := term, word
+		if len(short) > len(long) {
+			short, long = long, short
+		}
+
+		if short == long || len([]rune(short)) >= minStemLength && strings.HasPrefix(long, short) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type span struct{ start, end int }
+
+// words returns the byte spans of the runs of letters and digits of s.
+func words(s string) []span {
+	var (
+		res   []span
+		start = -1
+	)
+
+	for i, c := range s {
+		inWord := unicode.IsLetter(c) || unicode.IsDigit(c)
+
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			res = append(res, span{start, i})
+			start = -1
+		}
+	}
+
+	if start >= 0 {
+		res = append(res, span{start, len(s)})
+	}
+
+	return res
+}
@@ -0,0 +1,126 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	mock_service "github.com/ernur-eskermes/product-store/internal/service/mocks"
+	"github.com/ernur-eskermes/product-store/pkg/filters"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProduct_Search(t *testing.T) {
+	type mockBehavior func(r *mock_service.MockProductStorage, product core.Product)
+
+	found := func(r *mock_service.MockProductStorage, product core.Product) {
+		r.EXPECT().Search(gomock.Any(), gomock.Any(), core.ProductFilter{}, gomock.Any()).Return([]core.SearchResult{{Product: product, Score: 1.5}}, nil)
+	}
+
+	cases := []struct {
+		name          string
+		query         string
+		product       core.Product
+		expHighlights []core.Highlight
+		expErr        string
+		mockBehavior  mockBehavior
+	}{
+		{
+			name:    "word",
+			query:   "apple",
+			product: core.Product{Name: "Green Apple, apples and pineapple"},
+			expHighlights: []core.Highlight{
+				{Field: "name", Start: 6, End: 11},
+				{Field: "name", Start: 13, End: 19},
+			},
+			mockBehavior: found,
+		},
+		{
+			name:    "stem_and_sku",
+			query:   `"apples" A-12 -green`,
+			product: core.Product{SKU: "A-12", Name: "Green Äpfel apple"},
+			expHighlights: []core.Highlight{
+				{Field: "name", Start: 12, End: 17},
+				{Field: "sku", Start: 0, End: 1},
+				{Field: "sku", Start: 2, End: 4},
+			},
+			mockBehavior: found,
+		},
+		{
+			name:    "offsets_in_characters",
+			query:   "äpfel",
+			product: core.Product{Name: "Grüne Äpfel"},
+			expHighlights: []core.Highlight{
+				{Field: "name", Start: 6, End: 11},
+			},
+			mockBehavior: found,
+		},
+		{
+			name:   "error_when_calling_Search",
+			query:  "apple",
+			expErr: "error1",
+			mockBehavior: func(r *mock_service.MockProductStorage, _ core.Product) {
+				r.EXPECT().Search(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("error1"))
+			},
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			productService, productRepo, _ := mockProductService(t, nil)
+			s.mockBehavior(productRepo, s.product)
+
+			results, err := productService.Search(context.Background(), s.query, core.ProductFilter{}, filters.New(1, 10, "", "", nil))
+			if s.expErr != "" {
+				require.EqualError(t, err, s.expErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, []core.SearchResult{{Product: s.product, Score: 1.5, Highlights: s.expHighlights}}, results)
+		})
+	}
+}
+
+func TestProduct_Suggest(t *testing.T) {
+	cases := []struct {
+		name   string
+		prefix string
+		expKey string
+	}{
+		{
+			name:   "case",
+			prefix: "GREEN Ap",
+			expKey: "green ap",
+		},
+		{
+			name:   "accents",
+			prefix: "Crème Brû",
+			expKey: "creme bru",
+		},
+		{
+			name:   "spaces",
+			prefix: "  green \t apple",
+			expKey: "green apple",
+		},
+		{
+			name:   "trailing_space",
+			prefix: "green ",
+			expKey: "green ",
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			productService, productRepo, _ := mockProductService(t, nil)
+			productRepo.EXPECT().Suggest(gomock.Any(), s.expKey, 5).Return([]string{"Green Apple"}, nil)
+
+			names, err := productService.Suggest(context.Background(), s.prefix, 5)
+			require.NoError(t, err)
+			require.Equal(t, []string{"Green Apple"}, names)
+		})
+	}
+}
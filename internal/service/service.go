@@ -1,17 +1,43 @@
 package service
 
+import (
+	"github.com/ernur-eskermes/product-store/internal/config"
+	"github.com/ernur-eskermes/product-store/pkg/logger"
+)
+
 type Service struct {
-	Product *ProductService
+	Product    *ProductService
+	FetchJob   *FetchJobService
+	FeedSource *FeedSourceService
+	DropDir    *DropDirService
 }
 
 type Deps struct {
-	ProductStorage ProductStorage
+	Logger logger.Logger
+
+	ProductStorage      ProductStorage
+	PriceHistoryStorage PriceHistoryStorage
+	FetchJobStorage     FetchJobStorage
+	FeedSourceStorage   FeedSourceStorage
+	FeedCacheStorage    FeedCacheStorage
 
 	HTTPClient HTTPClient
+
+	FetchConfig     config.FetchConfig
+	JobsConfig      config.JobsConfig
+	SchedulerConfig config.SchedulerConfig
+	DropConfig      config.DropConfig
+	ListConfig      config.ListConfig
 }
 
 func New(deps Deps) *Service {
+	productService := NewProductService(deps.ProductStorage, deps.PriceHistoryStorage, deps.FeedCacheStorage, deps.HTTPClient, deps.FetchConfig, deps.ListConfig.PageTokenKey)
+	fetchJobService := NewFetchJobService(deps.FetchJobStorage, productService, deps.Logger, deps.JobsConfig)
+
 	return &Service{
-		Product: NewProductService(deps.ProductStorage, deps.HTTPClient),
+		Product:    productService,
+		FetchJob:   fetchJobService,
+		FeedSource: NewFeedSourceService(deps.FeedSourceStorage, fetchJobService, deps.Logger, deps.SchedulerConfig),
+		DropDir:    NewDropDirService(productService, deps.Logger, deps.DropConfig),
 	}
 }
@@ -3,14 +3,30 @@ package service_test
 import (
 	"testing"
 
+	"github.com/ernur-eskermes/product-store/internal/config"
 	"github.com/ernur-eskermes/product-store/internal/service"
+	mock_service "github.com/ernur-eskermes/product-store/internal/service/mocks"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
 
 func TestNew(t *testing.T) {
-	productService, productStorage := mockProductService(t, nil)
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
 
-	s := service.New(service.Deps{ProductStorage: productStorage})
+	productStorage := mock_service.NewMockProductStorage(mockCtl)
+	priceHistoryStorage := mock_service.NewMockPriceHistoryStorage(mockCtl)
+	feedCacheStorage := mock_service.NewMockFeedCacheStorage(mockCtl)
+
+	s := service.New(service.Deps{
+		ProductStorage:      productStorage,
+		PriceHistoryStorage: priceHistoryStorage,
+		FeedCacheStorage:    feedCacheStorage,
+		FetchConfig:         testFetchConfig,
+		ListConfig:          config.ListConfig{PageTokenKey: "key"},
+	})
+
+	productService := service.NewProductService(productStorage, priceHistoryStorage, feedCacheStorage, nil, testFetchConfig, "key")
 
 	require.Equal(t, productService, s.Product)
 }
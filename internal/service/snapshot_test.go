@@ -0,0 +1,119 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	mock_service "github.com/ernur-eskermes/product-store/internal/service/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProduct_FetchSnapshot(t *testing.T) {
+	type mockBehavior func(p *mock_service.MockProductStorage)
+
+	body := "PRODUCT NAME;PRICE\nApple;100\n"
+
+	// The products must be stored by the same run the snapshot discontinues for.
+	var stored core.FetchRun
+
+	storeProducts := func(p *mock_service.MockProductStorage) *gomock.Call {
+		return p.EXPECT().UpdateOrCreate(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, products []core.Product, run core.FetchRun) (core.WriteResult, error) {
+				require.Equal(t, "https://some-url.com", run.SourceURL)
+				require.False(t, run.ID.IsZero())
+				stored = run
+
+				return core.WriteResult{Unchanged: len(products)}, nil
+			},
+		)
+	}
+
+	discontinue := func(p *mock_service.MockProductStorage, n int, err error) *gomock.Call {
+		return p.EXPECT().Discontinue(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, run core.FetchRun) (int, error) {
+			require.Equal(t, stored, run)
+
+			return n, err
+		})
+	}
+
+	cases := []struct {
+		name                  string
+		body                  string
+		opts                  core.FetchOptions
+		expDiscontinued       int
+		expDiscontinueSkipped bool
+		expErr                string
+		mockBehavior          mockBehavior
+	}{
+		{
+			name:            "snapshot",
+			body:            body,
+			opts:            core.FetchOptions{Snapshot: true},
+			expDiscontinued: 2,
+			mockBehavior: func(p *mock_service.MockProductStorage) {
+				gomock.InOrder(storeProducts(p), discontinue(p, 2, nil))
+			},
+		},
+		{
+			name: "not_a_snapshot",
+			body: body,
+			mockBehavior: func(p *mock_service.MockProductStorage) {
+				storeProducts(p)
+			},
+		},
+		{
+			name:         "empty_snapshot",
+			body:         "PRODUCT NAME;PRICE\n",
+			opts:         core.FetchOptions{Snapshot: true},
+			mockBehavior: func(p *mock_service.MockProductStorage) {},
+		},
+		{
+			name:                  "rejected_rows",
+			body:                  body + "Pear;abc\n",
+			opts:                  core.FetchOptions{Snapshot: true},
+			expDiscontinueSkipped: true,
+			mockBehavior: func(p *mock_service.MockProductStorage) {
+				storeProducts(p)
+			},
+		},
+		{
+			name:   "error_when_discontinuing",
+			body:   body,
+			opts:   core.FetchOptions{Snapshot: true},
+			expErr: "error1",
+			mockBehavior: func(p *mock_service.MockProductStorage) {
+				gomock.InOrder(storeProducts(p), discontinue(p, 0, errors.New("error1")))
+			},
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			defer mockCtl.Finish()
+
+			httpClient := mock_service.NewMockHTTPClient(mockCtl)
+			productService, productRepo, _ := mockProductService(t, httpClient)
+
+			httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte(s.body)))}, nil)
+			s.mockBehavior(productRepo)
+
+			report, err := productService.Fetch(context.Background(), "https://some-url.com", s.opts, nil)
+			if s.expErr != "" {
+				require.EqualError(t, err, s.expErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, s.expDiscontinued, report.ProductsDiscontinued)
+			require.Equal(t, s.expDiscontinueSkipped, report.DiscontinueSkipped)
+		})
+	}
+}
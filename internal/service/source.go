@@ -0,0 +1,208 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+)
+
+// Feed is an opened feed. ContentType, ContentEncoding, ETag and LastModified are set
+// when the source knows them. NotModified is set, and Body left nil, when the feed has
+// not changed since the fetch described by the cache given to FeedOpener.Open.
+type Feed struct {
+	Body            io.ReadCloser
+	ContentType     string
+	ContentEncoding string
+	ETag            string
+	LastModified    string
+	NotModified     bool
+}
+
+// FeedRequest is a feed to open. Auth holds the credentials to present, if any, with a
+// named secret already resolved. Cache describes the last successful fetch of URL, if
+// any.
+type FeedRequest struct {
+	URL   *url.URL
+	Auth  *core.FeedAuth
+	Cache core.FeedCache
+}
+
+// FeedOpener opens the feeds of one URL scheme.
+type FeedOpener interface {
+	Open(ctx context.Context, req FeedRequest) (*Feed, error)
+}
+
+// RegisterOpener makes Fetch open URLs of scheme with o, in place of the opener it
+// used so far, if any.
+func (s *ProductService) RegisterOpener(scheme string, o FeedOpener) {
+	s.openers[strings.ToLower(scheme)] = o
+}
+
+func (s *ProductService) open(ctx context.Context, req FeedRequest) (*Feed, error) {
+	o, ok := s.openers[strings.ToLower(req.URL.Scheme)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", core.ErrUnsupportedScheme, req.URL.Scheme)
+	}
+
+	auth, err := s.resolveAuth(req.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Auth = auth
+
+	return o.Open(ctx, req)
+}
+
+// resolveAuth returns the credentials named by auth.Secret, or auth itself if it
+// names none.
+func (s *ProductService) resolveAuth(auth *core.FeedAuth) (*core.FeedAuth, error) {
+	if auth == nil || auth.Secret == "" {
+		return auth, nil
+	}
+
+	secret, ok := s.cfg.Secrets[auth.Secret]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", core.ErrUnknownSecret, auth.Secret)
+	}
+
+	return &core.FeedAuth{
+		Username:    secret.Username,
+		Password:    secret.Password,
+		BearerToken: secret.BearerToken,
+		HeaderName:  secret.HeaderName,
+		HeaderValue: secret.HeaderValue,
+	}, nil
+}
+
+// fileOpener opens file URLs naming a file under root. The ETag of a file is made of
+// its modification time and size.
+type fileOpener struct {
+	root string
+}
+
+func (o *fileOpener) Open(_ context.Context, req FeedRequest) (*Feed, error) {
+	u := req.URL
+	if u.Host != "" && u.Host != "localhost" {
+		return nil, fmt.Errorf("%w: %s is not a local file", core.ErrFeedOutsideRoot, u.Redacted())
+	}
+
+	path, err := o.resolve(u.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+
+		return nil, err
+	}
+
+	if info.IsDir() {
+		f.Close()
+
+		return nil, &core.FeedError{Err: fmt.Errorf("%s is a directory", u.Path)}
+	}
+
+	etag := fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size())
+	if req.Cache.ETag == etag {
+		f.Close()
+
+		return &Feed{NotModified: true}, nil
+	}
+
+	return &Feed{Body: f, ETag: etag}, nil
+}
+
+// resolve returns the path of the file named by p, following symbolic links, provided
+// it lies under root.
+func (o *fileOpener) resolve(p string) (string, error) {
+	root, err := filepath.Abs(o.root)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Clean(filepath.FromSlash(p))
+	if !filepath.IsAbs(path) || !within(root, path) {
+		return "", fmt.Errorf("%w: %s", core.ErrFeedOutsideRoot, p)
+	}
+
+	// Links are only followed once the path is known to be under root, so that the
+	// files outside it cannot be probed.
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return "", err
+	}
+
+	if path, err = filepath.EvalSymlinks(path); err != nil {
+		return "", err
+	}
+
+	if !within(root, path) {
+		return "", fmt.Errorf("%w: %s", core.ErrFeedOutsideRoot, p)
+	}
+
+	return path, nil
+}
+
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// dataOpener opens data URLs, which carry the feed itself, as in
+// "data:text/csv;base64,UFJPRFVDVC...". The media type, if any, is the content type of
+// the feed.
+type dataOpener struct{}
+
+func (dataOpener) Open(_ context.Context, req FeedRequest) (*Feed, error) {
+	contentType, body, err := parseDataURL(req.URL)
+	if err != nil {
+		return nil, &core.FeedError{Err: err}
+	}
+
+	return &Feed{Body: io.NopCloser(bytes.NewReader(body)), ContentType: contentType}, nil
+}
+
+func parseDataURL(u *url.URL) (string, []byte, error) {
+	opaque := u.Opaque
+	if u.RawQuery != "" || u.ForceQuery {
+		opaque += "?" + u.RawQuery
+	}
+
+	meta, data, ok := strings.Cut(opaque, ",")
+	if !ok {
+		return "", nil, errors.New("invalid data URL: missing comma")
+	}
+
+	data, err := url.PathUnescape(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid data URL: %w", err)
+	}
+
+	if !strings.HasSuffix(meta, ";base64") {
+		return meta, []byte(data), nil
+	}
+
+	body, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid data URL: %w", err)
+	}
+
+	return strings.TrimSuffix(meta, ";base64"), body, nil
+}
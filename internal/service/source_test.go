@@ -0,0 +1,146 @@
+package service_test
+
+import (
+	"context"
+	"encoding/base64"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	"github.com/ernur-eskermes/product-store/internal/service"
+	mock_service "github.com/ernur-eskermes/product-store/internal/service/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProduct_FetchSources(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	csv := "PRODUCT NAME;PRICE\nTest Product;1000\n"
+	products := []core.Product{{Name: "Test Product", Price: dollars(1000)}}
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "products.csv"), []byte(csv), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.csv"), []byte(csv), 0o600))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.csv"), filepath.Join(root, "link.csv")))
+
+	fileURL := func(path string) string {
+		return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+	}
+
+	cfg := testFetchConfig
+	cfg.FileRoot = root
+
+	cases := []struct {
+		name      string
+		url       string
+		expStored []core.Product
+		expErr    error
+	}{
+		{
+			name:      "file",
+			url:       fileURL(filepath.Join(root, "products.csv")),
+			expStored: products,
+		},
+		{
+			name:   "file_outside_root",
+			url:    fileURL(filepath.Join(root, "..", filepath.Base(outside), "secret.csv")),
+			expErr: core.ErrFeedOutsideRoot,
+		},
+		{
+			name:   "link_outside_root",
+			url:    fileURL(filepath.Join(root, "link.csv")),
+			expErr: core.ErrFeedOutsideRoot,
+		},
+		{
+			name:   "missing_file",
+			url:    fileURL(filepath.Join(root, "missing.csv")),
+			expErr: fs.ErrNotExist,
+		},
+		{
+			name:      "data",
+			url:       "data:text/csv," + url.PathEscape(csv),
+			expStored: products,
+		},
+		{
+			name:      "base64_data",
+			url:       "data:application/json;base64," + base64.StdEncoding.EncodeToString([]byte(`[{"name":"Test Product","price":1000}]`)),
+			expStored: products,
+		},
+		{
+			name:   "unsupported_scheme",
+			url:    "ftp://some-url.com/products.csv",
+			expErr: core.ErrUnsupportedScheme,
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			defer mockCtl.Finish()
+
+			productRepo := mock_service.NewMockProductStorage(mockCtl)
+			feedCacheRepo := mock_service.NewMockFeedCacheStorage(mockCtl)
+			feedCacheRepo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(core.FeedCache{}, nil).AnyTimes()
+			feedCacheRepo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+			productService := service.NewProductService(productRepo, mock_service.NewMockPriceHistoryStorage(mockCtl), feedCacheRepo, nil, cfg, testPageTokenKey)
+
+			if s.expStored != nil {
+				productRepo.EXPECT().UpdateOrCreate(gomock.Any(), s.expStored, gomock.Any()).Return(core.WriteResult{Inserted: len(s.expStored)}, nil)
+			}
+
+			_, err := productService.Fetch(context.Background(), s.url, core.FetchOptions{}, nil)
+			if s.expErr != nil {
+				require.ErrorIs(t, err, s.expErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestProduct_FetchFileNotModified(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "products.csv")
+	require.NoError(t, os.WriteFile(path, []byte("PRODUCT NAME;PRICE\nTest Product;1000\n"), 0o600))
+
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	productRepo := mock_service.NewMockProductStorage(mockCtl)
+	feedCacheRepo := mock_service.NewMockFeedCacheStorage(mockCtl)
+
+	cfg := testFetchConfig
+	cfg.FileRoot = root
+
+	productService := service.NewProductService(productRepo, mock_service.NewMockPriceHistoryStorage(mockCtl), feedCacheRepo, nil, cfg, testPageTokenKey)
+
+	var saved core.FeedCache
+
+	feedCacheRepo.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, string) (core.FeedCache, error) {
+		return saved, nil
+	}).Times(2)
+	feedCacheRepo.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, cache core.FeedCache) error {
+		saved = cache
+
+		return nil
+	})
+	productRepo.EXPECT().UpdateOrCreate(gomock.Any(), gomock.Any(), gomock.Any()).Return(core.WriteResult{Inserted: 1}, nil)
+
+	u := (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+
+	report, err := productService.Fetch(context.Background(), u, core.FetchOptions{}, nil)
+	require.NoError(t, err)
+	require.Empty(t, report.Skipped)
+	require.NotEmpty(t, saved.ETag)
+
+	report, err = productService.Fetch(context.Background(), u, core.FetchOptions{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, core.FetchSkipNotModified, report.Skipped)
+}
@@ -0,0 +1,82 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	mock_service "github.com/ernur-eskermes/product-store/internal/service/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProduct_Upload(t *testing.T) {
+	type mockBehavior func(p *mock_service.MockProductStorage)
+
+	csv := "PRODUCT NAME;PRICE\nTest Product;1000\nTest Product2;2538\n"
+	products := []core.Product{{Name: "Test Product", Price: dollars(1000)}, {Name: "Test Product2", Price: dollars(2538)}}
+
+	cases := []struct {
+		name         string
+		file         string
+		body         []byte
+		opts         core.FetchOptions
+		expErr       string
+		mockBehavior mockBehavior
+	}{
+		{
+			name: "csv",
+			file: "products.csv",
+			body: []byte(csv),
+			mockBehavior: func(p *mock_service.MockProductStorage) {
+				p.EXPECT().UpdateOrCreate(gomock.Any(), products, gomock.Any()).Return(core.WriteResult{Inserted: 2}, nil)
+			},
+		},
+		{
+			name: "gzip",
+			file: "products.csv.gz",
+			body: gzipFeed(t, csv),
+			mockBehavior: func(p *mock_service.MockProductStorage) {
+				p.EXPECT().UpdateOrCreate(gomock.Any(), products, gomock.Any()).Return(core.WriteResult{Inserted: 2}, nil)
+			},
+		},
+		{
+			name: "zip",
+			file: "products.zip",
+			body: zipFeed(t, map[string]string{"products.json": `[{"name":"Test Product","price":1000},{"name":"Test Product2","price":2538}]`}, "products.json"),
+			mockBehavior: func(p *mock_service.MockProductStorage) {
+				p.EXPECT().UpdateOrCreate(gomock.Any(), products, gomock.Any()).Return(core.WriteResult{Inserted: 2}, nil)
+			},
+		},
+		{
+			name:   "error_when_storing_batch",
+			file:   "products.csv",
+			body:   []byte(csv),
+			expErr: "error1",
+			mockBehavior: func(p *mock_service.MockProductStorage) {
+				p.EXPECT().UpdateOrCreate(gomock.Any(), gomock.Any(), gomock.Any()).Return(core.WriteResult{}, errors.New("error1"))
+			},
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			productService, productRepo, _ := mockProductService(t, nil)
+
+			s.mockBehavior(productRepo)
+
+			report, err := productService.Upload(context.Background(), s.file, bytes.NewReader(s.body), s.opts)
+			if s.expErr != "" {
+				require.EqualError(t, err, s.expErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, 2, report.RowsAccepted)
+			require.Equal(t, int64(len(s.body)), report.BytesDownloaded)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+)
+
+type rowPosition struct {
+	file string
+	line int
+}
+
+// rowValidator checks decoded products against the rules every feed must follow. It
+// remembers up to maxKeys of the product keys it has seen, so one validator must be
+// used per fetch. Keys seen past those are not remembered, and untracked is set.
+type rowValidator struct {
+	seen      map[core.ProductKey]rowPosition
+	maxKeys   int
+	untracked bool
+}
+
+func newRowValidator(maxKeys int) *rowValidator {
+	return &rowValidator{seen: make(map[core.ProductKey]rowPosition), maxKeys: maxKeys}
+}
+
+func (v *rowValidator) validate(file string, d FeedDecoder, p core.Product) *core.RowError {
+	reject := func(field, reason string) *core.RowError {
+		return &core.RowError{File: file, Line: d.Line(), Column: d.Column(field), Reason: reason}
+	}
+
+	switch {
+	case strings.TrimSpace(p.Name) == "":
+		return reject(core.ProductFieldName, errEmptyValue.Error())
+	case utf8.RuneCountInString(p.Name) > core.MaxProductNameLength:
+		return reject(core.ProductFieldName, fmt.Sprintf("must be at most %d characters", core.MaxProductNameLength))
+	case utf8.RuneCountInString(p.SKU) > core.MaxProductSKULength:
+		return reject(core.ProductFieldSKU, fmt.Sprintf("must be at most %d characters", core.MaxProductSKULength))
+	case p.Price.Amount < 0:
+		return reject(core.ProductFieldPrice, "must not be negative")
+	}
+
+	keyField := core.ProductFieldName
+	if p.SKU != "" {
+		keyField = core.ProductFieldSKU
+	}
+
+	if first, ok := v.seen[p.Key()]; ok {
+		if first.file != file {
+			return reject(keyField, fmt.Sprintf("duplicate of %s line %d", first.file, first.line))
+		}
+
+		return reject(keyField, fmt.Sprintf("duplicate of line %d", first.line))
+	}
+
+	if len(v.seen) >= v.maxKeys {
+		v.untracked = true
+
+		return nil
+	}
+
+	v.seen[p.Key()] = rowPosition{file: file, line: d.Line()}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	mock_service "github.com/ernur-eskermes/product-store/internal/service/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProduct_FetchValidation(t *testing.T) {
+	longName := strings.Repeat("я", core.MaxProductNameLength+1)
+
+	cases := []struct {
+		name      string
+		url       string
+		body      string
+		expResp   []core.Product
+		expReport core.FetchReport
+	}{
+		{
+			name: "csv",
+			url:  "https://some-url.com/feed.csv",
+			body: "PRODUCT NAME;PRICE\nApple;100\n  ;200\nPear;-5\n" + longName + ";10\nPlum;\nApple;150\nPeach;1.999\nCherry;300\n",
+			expResp: []core.Product{
+				{Name: "Apple", Price: dollars(100)},
+				{Name: "Cherry", Price: dollars(300)},
+			},
+			expReport: core.FetchReport{
+				RowsRead:         8,
+				RowsAccepted:     2,
+				RowsRejected:     6,
+				ProductsInserted: 2,
+				RejectedRows: []core.RowError{
+					{Line: 3, Column: "PRODUCT NAME", Reason: "must not be empty"},
+					{Line: 4, Column: "PRICE", Reason: "must not be negative"},
+					{Line: 5, Column: "PRODUCT NAME", Reason: "must be at most 255 characters"},
+				},
+			},
+		},
+		{
+			name: "ndjson",
+			url:  "https://some-url.com/feed.ndjson",
+			body: "{\"name\": \"Apple\", \"price\": 100}\n{\"name\": \"Pear\", \"price\": \n{\"name\": 5, \"price\": 100}\n{\"name\": \"Plum\", \"price\": \"abc\"}\n{\"name\": \"Apple\", \"price\": 100}\n",
+			expResp: []core.Product{
+				{Name: "Apple", Price: dollars(100)},
+			},
+			expReport: core.FetchReport{
+				RowsRead:         5,
+				RowsAccepted:     1,
+				RowsRejected:     4,
+				ProductsInserted: 1,
+				RejectedRows: []core.RowError{
+					{Line: 2, Reason: "invalid json: unexpected end of JSON input"},
+					{Line: 3, Column: "name", Reason: "unexpected number value"},
+					{Line: 4, Column: "price", Reason: "must be a decimal number"},
+				},
+			},
+		},
+		{
+			name: "json",
+			url:  "https://some-url.com/feed.json",
+			body: `[{"name": "Apple", "price": 100}, {"name": "Pear"}, {"name": "Plum", "price": 200}]`,
+			expResp: []core.Product{
+				{Name: "Apple", Price: dollars(100)},
+				{Name: "Plum", Price: dollars(200)},
+			},
+			expReport: core.FetchReport{
+				RowsRead:         3,
+				RowsAccepted:     2,
+				RowsRejected:     1,
+				ProductsInserted: 2,
+				RejectedRows:     []core.RowError{{Line: 2, Column: "price", Reason: "must not be empty"}},
+			},
+		},
+		{
+			name: "csv_with_sku",
+			url:  "https://some-url.com/feed.csv",
+			body: "SKU;PRODUCT NAME;PRICE\nA-1;Apple;100\nA-2;Apple;120\nA-1;Pear;200\n;Apple;300\n",
+			expResp: []core.Product{
+				{SKU: "A-1", Name: "Apple", Price: dollars(100)},
+				{SKU: "A-2", Name: "Apple", Price: dollars(120)},
+				{Name: "Apple", Price: dollars(300)},
+			},
+			expReport: core.FetchReport{
+				RowsRead:         4,
+				RowsAccepted:     3,
+				RowsRejected:     1,
+				ProductsInserted: 3,
+				RejectedRows:     []core.RowError{{Line: 4, Column: "SKU", Reason: "duplicate of line 2"}},
+			},
+		},
+		{
+			name: "xml",
+			url:  "https://some-url.com/feed.xml",
+			body: "<products>\n<product name=\"Apple\" price=\"100\"/>\n<product name=\"Apple\" price=\"200\"/>\n</products>",
+			expResp: []core.Product{
+				{Name: "Apple", Price: dollars(100)},
+			},
+			expReport: core.FetchReport{
+				RowsRead:         2,
+				RowsAccepted:     1,
+				RowsRejected:     1,
+				ProductsInserted: 1,
+				RejectedRows:     []core.RowError{{Line: 3, Column: "name", Reason: "duplicate of line 2"}},
+			},
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			defer mockCtl.Finish()
+
+			httpClient := mock_service.NewMockHTTPClient(mockCtl)
+			productService, productRepo, _ := mockProductService(t, httpClient)
+
+			httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte(s.body)))}, nil)
+
+			stored := make([]core.Product, 0)
+			productRepo.EXPECT().UpdateOrCreate(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, products []core.Product, _ interface{}) (core.WriteResult, error) {
+					stored = append(stored, products...)
+
+					return core.WriteResult{Inserted: len(products)}, nil
+				},
+			).AnyTimes()
+
+			report, err := productService.Fetch(context.Background(), s.url, core.FetchOptions{}, nil)
+			require.NoError(t, err)
+			require.Equal(t, s.expResp, stored)
+
+			require.Positive(t, report.Duration)
+			report.Duration = 0
+			s.expReport.BytesDownloaded = int64(len(s.body))
+			require.Equal(t, s.expReport, report)
+		})
+	}
+}
+
+func TestProduct_FetchUntrackedKeys(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	httpClient := mock_service.NewMockHTTPClient(mockCtl)
+
+	cfg := testFetchConfig
+	cfg.MaxTrackedKeys = 2
+	productService, productRepo, _ := mockProductServiceWithConfig(t, httpClient, cfg)
+
+	body := "PRODUCT NAME;PRICE\nApple;100\nPear;200\nApple;300\nPlum;400\nPlum;500\n"
+
+	httpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}, nil)
+
+	stored := make([]core.Product, 0)
+	productRepo.EXPECT().UpdateOrCreate(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, products []core.Product, _ interface{}) (core.WriteResult, error) {
+			stored = append(stored, products...)
+
+			return core.WriteResult{Inserted: len(products)}, nil
+		},
+	).AnyTimes()
+
+	report, err := productService.Fetch(context.Background(), "https://some-url.com/feed.csv", core.FetchOptions{}, nil)
+	require.NoError(t, err)
+
+	// Apple and Pear are remembered, so the second Apple is rejected. Plum is not, so
+	// its duplicate goes unchecked.
+	require.Equal(t, []core.Product{
+		{Name: "Apple", Price: dollars(100)},
+		{Name: "Pear", Price: dollars(200)},
+		{Name: "Plum", Price: dollars(400)},
+		{Name: "Plum", Price: dollars(500)},
+	}, stored)
+	require.Equal(t, []core.RowError{{Line: 4, Column: "PRODUCT NAME", Reason: "duplicate of line 2"}}, report.RejectedRows)
+	require.True(t, report.DuplicatesUnchecked)
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+)
+
+// xmlProduct is a <product> element of an XML feed. SKU, name, price and currency may
+// be given either as child elements or as attributes.
+type xmlProduct struct {
+	SKU          string `xml:"sku"`
+	SKUAttr      string `xml:"sku,attr"`
+	Name         string `xml:"name"`
+	NameAttr     string `xml:"name,attr"`
+	Price        string `xml:"price"`
+	PriceAttr    string `xml:"price,attr"`
+	Currency     string `xml:"currency"`
+	CurrencyAttr string `xml:"currency,attr"`
+}
+
+// xmlDecoder reads products from an XML feed. Every <product> element is a product, no
+// matter how deeply it is nested, and elements are decoded one at a time. Prices are in
+// currency unless a product gives its own.
+type xmlDecoder struct {
+	d        *xml.Decoder
+	currency string
+	line     int
+}
+
+func newXMLDecoder(in io.Reader, currency string) *xmlDecoder {
+	return &xmlDecoder{d: xml.NewDecoder(in), currency: currency}
+}
+
+// Decode returns the next product in the feed, or io.EOF once the feed is exhausted.
+func (d *xmlDecoder) Decode() (core.Product, error) {
+	for {
+		tok, err := d.d.Token()
+		if err != nil {
+			return core.Product{}, err
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok || !strings.EqualFold(start.Name.Local, "product") {
+			continue
+		}
+
+		d.line, _ = d.d.InputPos()
+
+		var p xmlProduct
+		if err = d.d.DecodeElement(&p, &start); err != nil {
+			return core.Product{}, err
+		}
+
+		return p.product(d.line, d.currency)
+	}
+}
+
+func (d *xmlDecoder) Line() int {
+	return d.line
+}
+
+func (d *xmlDecoder) Column(field string) string {
+	return field
+}
+
+func (p xmlProduct) product(line int, defaultCurrency string) (core.Product, error) {
+	sku, name, price, currency := p.SKU, p.Name, p.Price, p.Currency
+	if currency == "" {
+		currency = p.CurrencyAttr
+	}
+
+	if sku == "" {
+		sku = p.SKUAttr
+	}
+
+	if name == "" {
+		name = p.NameAttr
+	}
+
+	if price == "" {
+		price = p.PriceAttr
+	}
+
+	res := core.Product{SKU: strings.TrimSpace(sku), Name: strings.TrimSpace(name)}
+
+	var (
+		field string
+		err   error
+	)
+
+	if res.Price, field, err = parsePrice(price, currency, defaultCurrency); err != nil {
+		return core.Product{}, &core.RowError{Line: line, Column: field, Reason: err.Error()}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type FeedCache struct {
+	db *mongo.Collection
+}
+
+func NewFeedCache(db *mongo.Collection) *FeedCache {
+	return &FeedCache{
+		db: db,
+	}
+}
+
+// Get returns what is cached for url, or a zero FeedCache if it was never fetched.
+func (r *FeedCache) Get(ctx context.Context, url string) (core.FeedCache, error) {
+	var cache core.FeedCache
+
+	err := r.db.FindOne(ctx, bson.D{{Key: "_id", Value: url}}).Decode(&cache)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return core.FeedCache{}, nil
+	}
+
+	return cache, err
+}
+
+func (r *FeedCache) Save(ctx context.Context, cache core.FeedCache) error {
+	_, err := r.db.ReplaceOne(ctx, bson.D{{Key: "_id", Value: cache.URL}}, cache, options.Replace().SetUpsert(true))
+
+	return err
+}
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	"github.com/ernur-eskermes/product-store/pkg/filters"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type FeedSource struct {
+	db *mongo.Collection
+}
+
+func NewFeedSource(db *mongo.Collection) *FeedSource {
+	return &FeedSource{
+		db: db,
+	}
+}
+
+func (r *FeedSource) Create(ctx context.Context, source core.FeedSource) (core.FeedSource, error) {
+	res, err := r.db.InsertOne(ctx, source)
+	if err != nil {
+		return core.FeedSource{}, err
+	}
+
+	source.ID, _ = res.InsertedID.(primitive.ObjectID)
+
+	return source, nil
+}
+
+func (r *FeedSource) GetByID(ctx context.Context, id primitive.ObjectID) (core.FeedSource, error) {
+	var source core.FeedSource
+	if err := r.db.FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&source); err != nil {
+		return core.FeedSource{}, feedSourceError(err)
+	}
+
+	return source, nil
+}
+
+func (r *FeedSource) GetAll(ctx context.Context, f *filters.Filters) ([]core.FeedSource, error) {
+	opts := options.FindOptions{}
+	opts.SetSkip(f.Offset())
+	opts.SetLimit(f.Limit())
+	opts.SetSort(sortBy(f))
+
+	cur, err := r.db.Find(ctx, bson.D{}, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]core.FeedSource, 0)
+	if err = cur.All(ctx, &sources); err != nil {
+		return nil, err
+	}
+
+	return sources, nil
+}
+
+func (r *FeedSource) GetTotalRecords(ctx context.Context) (int64, error) {
+	return r.db.CountDocuments(ctx, bson.D{})
+}
+
+// Update replaces the editable fields of a source and returns the stored result.
+func (r *FeedSource) Update(ctx context.Context, source core.FeedSource) (core.FeedSource, error) {
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "name", Value: source.Name},
+		{Key: "url", Value: source.URL},
+		{Key: "options", Value: source.Options},
+		{Key: "schedule", Value: source.Schedule},
+		{Key: "enabled", Value: source.Enabled},
+		{Key: "next_run_at", Value: source.NextRunAt},
+		{Key: "updated_at", Value: source.UpdatedAt},
+	}}}
+
+	var res core.FeedSource
+	if err := r.db.FindOneAndUpdate(ctx, bson.D{{Key: "_id", Value: source.ID}}, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&res); err != nil {
+		return core.FeedSource{}, feedSourceError(err)
+	}
+
+	return res, nil
+}
+
+func (r *FeedSource) Delete(ctx context.Context, id primitive.ObjectID) error {
+	res, err := r.db.DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return err
+	}
+
+	if res.DeletedCount == 0 {
+		return core.ErrFeedSourceNotFound
+	}
+
+	return nil
+}
+
+// GetDue returns up to limit enabled sources whose next run is at or before now,
+// the most overdue first.
+func (r *FeedSource) GetDue(ctx context.Context, now time.Time, limit int64) ([]core.FeedSource, error) {
+	filter := bson.D{
+		{Key: "enabled", Value: true},
+		{Key: "next_run_at", Value: bson.D{{Key: "$lte", Value: now}}},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "next_run_at", Value: 1}}).SetLimit(limit)
+
+	cur, err := r.db.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]core.FeedSource, 0)
+	if err = cur.All(ctx, &sources); err != nil {
+		return nil, err
+	}
+
+	return sources, nil
+}
+
+// Advance moves a due source on to its next run. The update only applies while the
+// source is still due at the run it was read with, so when several instances see the
+// same due source exactly one of them gets true back and runs it.
+func (r *FeedSource) Advance(ctx context.Context, source core.FeedSource, next, now time.Time) (bool, error) {
+	filter := bson.D{
+		{Key: "_id", Value: source.ID},
+		{Key: "enabled", Value: true},
+		{Key: "next_run_at", Value: source.NextRunAt},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "next_run_at", Value: next},
+		{Key: "last_run_at", Value: now},
+	}}}
+
+	res, err := r.db.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return res.ModifiedCount == 1, nil
+}
+
+// EnsureIndexes creates the index the scheduler finds due sources by.
+func (r *FeedSource) EnsureIndexes(ctx context.Context) error {
+	_, err := r.db.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "enabled", Value: 1}, {Key: "next_run_at", Value: 1}},
+	})
+
+	return err
+}
+
+func (r *FeedSource) SetLastJob(ctx context.Context, id, jobID primitive.ObjectID) error {
+	_, err := r.db.UpdateOne(ctx, bson.D{{Key: "_id", Value: id}}, bson.D{{Key: "$set", Value: bson.D{
+		{Key: "last_job_id", Value: jobID},
+	}}})
+
+	return err
+}
+
+func feedSourceError(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return core.ErrFeedSourceNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,247 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	"github.com/ernur-eskermes/product-store/pkg/filters"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type FetchJob struct {
+	db *mongo.Collection
+}
+
+func NewFetchJob(db *mongo.Collection) *FetchJob {
+	return &FetchJob{
+		db: db,
+	}
+}
+
+func (r *FetchJob) Create(ctx context.Context, job core.FetchJob) (core.FetchJob, error) {
+	res, err := r.db.InsertOne(ctx, job)
+	if err != nil {
+		return core.FetchJob{}, err
+	}
+
+	job.ID, _ = res.InsertedID.(primitive.ObjectID)
+
+	return job, nil
+}
+
+func (r *FetchJob) GetByID(ctx context.Context, id primitive.ObjectID) (core.FetchJob, error) {
+	var job core.FetchJob
+	if err := r.db.FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&job); err != nil {
+		return core.FetchJob{}, fetchJobError(err)
+	}
+
+	return job, nil
+}
+
+func (r *FetchJob) GetAll(ctx context.Context, filter core.FetchJobFilter, f *filters.Filters) ([]core.FetchJob, error) {
+	opts := options.FindOptions{}
+	opts.SetSkip(f.Offset())
+	opts.SetLimit(f.Limit())
+	opts.SetSort(sortBy(f))
+
+	cur, err := r.db.Find(ctx, fetchJobQuery(filter), &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]core.FetchJob, 0)
+	if err = cur.All(ctx, &jobs); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+func (r *FetchJob) GetTotalRecords(ctx context.Context, filter core.FetchJobFilter) (int64, error) {
+	return r.db.CountDocuments(ctx, fetchJobQuery(filter))
+}
+
+// Claim atomically hands the oldest pending job to workerID. Running jobs whose owner
+// stopped sending heartbeats before staleBefore are claimed as well, so a job survives
+// the instance that picked it up going away. Such jobs that were asked to cancel are
+// not run again but marked cancelled.
+func (r *FetchJob) Claim(ctx context.Context, workerID string, staleBefore time.Time) (core.FetchJob, error) {
+	now := time.Now().UTC()
+
+	if _, err := r.db.UpdateMany(ctx, staleCancelledFetchJobFilter(staleBefore), bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: core.FetchJobCancelled},
+		{Key: "finished_at", Value: now},
+	}}, {Key: "$unset", Value: fetchJobSecretValues}}); err != nil {
+		return core.FetchJob{}, err
+	}
+
+	filter := claimFetchJobFilter(staleBefore)
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: core.FetchJobRunning},
+		{Key: "worker_id", Value: workerID},
+		{Key: "started_at", Value: now},
+		{Key: "heartbeat_at", Value: now},
+	}}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var job core.FetchJob
+	if err := r.db.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job); err != nil {
+		return core.FetchJob{}, fetchJobError(err)
+	}
+
+	return job, nil
+}
+
+// Heartbeat reports progress of a job owned by workerID and returns its current state,
+// so the worker can see whether cancellation was requested. It returns
+// core.ErrFetchJobNotFound once the job has been claimed by another worker.
+func (r *FetchJob) Heartbeat(ctx context.Context, id primitive.ObjectID, workerID string, rowsProcessed int) (core.FetchJob, error) {
+	filter := bson.D{
+		{Key: "_id", Value: id},
+		{Key: "worker_id", Value: workerID},
+		{Key: "status", Value: core.FetchJobRunning},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "heartbeat_at", Value: time.Now().UTC()},
+		{Key: "rows_processed", Value: rowsProcessed},
+	}}}
+
+	var job core.FetchJob
+	if err := r.db.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&job); err != nil {
+		return core.FetchJob{}, fetchJobError(err)
+	}
+
+	return job, nil
+}
+
+// EnsureIndexes creates the indexes workers claim jobs by.
+func (r *FetchJob) EnsureIndexes(ctx context.Context) error {
+	_, err := r.db.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "heartbeat_at", Value: 1}}},
+	})
+
+	return err
+}
+
+// fetchJobSecretValues are the secret values of the credentials of a job, removed once
+// it has finished so that they are not kept around in plain text. The rest of the
+// credentials, as they are shown, remain.
+var fetchJobSecretValues = bson.D{
+	{Key: "options.auth.password", Value: ""},
+	{Key: "options.auth.bearer_token", Value: ""},
+	{Key: "options.auth.header_value", Value: ""},
+}
+
+func (r *FetchJob) Finish(ctx context.Context, job core.FetchJob) error {
+	_, err := r.db.UpdateOne(ctx, bson.D{{Key: "_id", Value: job.ID}, {Key: "worker_id", Value: job.WorkerID}}, bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: job.Status},
+		{Key: "rows_processed", Value: job.RowsProcessed},
+		{Key: "errors", Value: job.Errors},
+		{Key: "report", Value: job.Report},
+		{Key: "finished_at", Value: job.FinishedAt},
+	}}, {Key: "$unset", Value: fetchJobSecretValues}})
+
+	return err
+}
+
+// Release puts a job owned by workerID back into the queue.
+func (r *FetchJob) Release(ctx context.Context, id primitive.ObjectID, workerID string) error {
+	_, err := r.db.UpdateOne(ctx, bson.D{{Key: "_id", Value: id}, {Key: "worker_id", Value: workerID}}, bson.D{
+		{Key: "$set", Value: bson.D{{Key: "status", Value: core.FetchJobPending}}},
+		{Key: "$unset", Value: bson.D{{Key: "worker_id", Value: ""}, {Key: "heartbeat_at", Value: ""}}},
+	})
+
+	return err
+}
+
+// Cancel cancels a pending job right away. A running job is only flagged, the worker
+// that owns it stops and marks it cancelled on its next heartbeat.
+func (r *FetchJob) Cancel(ctx context.Context, id primitive.ObjectID) (core.FetchJob, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var job core.FetchJob
+
+	err := r.db.FindOneAndUpdate(ctx,
+		bson.D{{Key: "_id", Value: id}, {Key: "status", Value: core.FetchJobPending}},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "status", Value: core.FetchJobCancelled},
+			{Key: "cancel_requested", Value: true},
+			{Key: "finished_at", Value: time.Now().UTC()},
+		}}, {Key: "$unset", Value: fetchJobSecretValues}},
+		opts,
+	).Decode(&job)
+	if err == nil {
+		return job, nil
+	}
+
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return core.FetchJob{}, err
+	}
+
+	err = r.db.FindOneAndUpdate(ctx,
+		bson.D{{Key: "_id", Value: id}, {Key: "status", Value: core.FetchJobRunning}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "cancel_requested", Value: true}}}},
+		opts,
+	).Decode(&job)
+	if err == nil {
+		return job, nil
+	}
+
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return core.FetchJob{}, err
+	}
+
+	if _, err = r.GetByID(ctx, id); err != nil {
+		return core.FetchJob{}, err
+	}
+
+	return core.FetchJob{}, core.ErrFetchJobFinished
+}
+
+// claimFetchJobFilter matches pending jobs and running jobs whose owner stopped sending
+// heartbeats before staleBefore, unless cancellation of those was requested.
+func claimFetchJobFilter(staleBefore time.Time) bson.D {
+	return bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "status", Value: core.FetchJobPending}},
+		bson.D{
+			{Key: "status", Value: core.FetchJobRunning},
+			{Key: "heartbeat_at", Value: bson.D{{Key: "$lt", Value: staleBefore}}},
+			{Key: "cancel_requested", Value: bson.D{{Key: "$ne", Value: true}}},
+		},
+	}}}
+}
+
+// staleCancelledFetchJobFilter matches running jobs that were asked to cancel but whose
+// owner stopped sending heartbeats before staleBefore, so nobody will finish them.
+func staleCancelledFetchJobFilter(staleBefore time.Time) bson.D {
+	return bson.D{
+		{Key: "status", Value: core.FetchJobRunning},
+		{Key: "heartbeat_at", Value: bson.D{{Key: "$lt", Value: staleBefore}}},
+		{Key: "cancel_requested", Value: true},
+	}
+}
+
+func fetchJobQuery(filter core.FetchJobFilter) bson.D {
+	query := bson.D{}
+	if filter.Status != "" {
+		query = append(query, bson.E{Key: "status", Value: filter.Status})
+	}
+
+	return query
+}
+
+func fetchJobError(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return core.ErrFetchJobNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchJob_ClaimFilter(t *testing.T) {
+	now := time.Date(2022, 7, 1, 10, 0, 0, 0, time.UTC)
+	staleBefore := now.Add(-time.Minute)
+
+	tests := []struct {
+		name           string
+		job            core.FetchJob
+		claimed        bool
+		staleCancelled bool
+	}{
+		{
+			name:    "pending",
+			job:     core.FetchJob{Status: core.FetchJobPending},
+			claimed: true,
+		},
+		{
+			name: "running",
+			job:  core.FetchJob{Status: core.FetchJobRunning, HeartbeatAt: now},
+		},
+		{
+			name:    "stale",
+			job:     core.FetchJob{Status: core.FetchJobRunning, HeartbeatAt: now.Add(-time.Hour)},
+			claimed: true,
+		},
+		{
+			name: "running cancel requested",
+			job:  core.FetchJob{Status: core.FetchJobRunning, HeartbeatAt: now, CancelRequested: true},
+		},
+		{
+			name:           "stale cancel requested",
+			job:            core.FetchJob{Status: core.FetchJobRunning, HeartbeatAt: now.Add(-time.Hour), CancelRequested: true},
+			staleCancelled: true,
+		},
+		{
+			name: "cancelled",
+			job:  core.FetchJob{Status: core.FetchJobCancelled, HeartbeatAt: now.Add(-time.Hour), CancelRequested: true},
+		},
+		{
+			name: "succeeded",
+			job:  core.FetchJob{Status: core.FetchJobSucceeded, HeartbeatAt: now.Add(-time.Hour)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := document(t, tt.job)
+
+			require.Equal(t, tt.claimed, matches(t, claimFetchJobFilter(staleBefore), doc))
+			require.Equal(t, tt.staleCancelled, matches(t, staleCancelledFetchJobFilter(staleBefore), doc))
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package storage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// document returns v as it is stored.
+func document(t *testing.T, v interface{}) bson.M {
+	t.Helper()
+
+	raw, err := bson.Marshal(v)
+	require.NoError(t, err)
+
+	var doc bson.M
+	require.NoError(t, bson.Unmarshal(raw, &doc))
+
+	return doc
+}
+
+// stored returns the value v is stored as.
+func stored(t *testing.T, v interface{}) interface{} {
+	t.Helper()
+
+	return document(t, bson.D{{Key: "v", Value: v}})["v"]
+}
+
+// matches reports whether the query filter matches doc. It understands the subset of
+// the query language the storage builds, with the simple collation.
+func matches(t *testing.T, filter bson.D, doc bson.M) bool {
+	t.Helper()
+
+	for _, e := range filter {
+		switch e.Key {
+		case "$or", "$and":
+			clauses, ok := e.Value.(bson.A)
+			require.True(t, ok, "%s takes an array", e.Key)
+
+			anyMatch := false
+			allMatch := true
+
+			for _, clause := range clauses {
+				clause, ok := clause.(bson.D)
+				require.True(t, ok, "%s takes documents", e.Key)
+
+				m := matches(t, clause, doc)
+				anyMatch = anyMatch || m
+				allMatch = allMatch && m
+			}
+
+			if e.Key == "$or" && !anyMatch || e.Key == "$and" && !allMatch {
+				return false
+			}
+		default:
+			value, found := lookup(doc, e.Key)
+			if !fieldMatches(t, value, found, e.Value) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func lookup(doc bson.M, path string) (interface{}, bool) {
+	var value interface{} = doc
+
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(bson.M)
+		if !ok {
+			return nil, false
+		}
+
+		if value, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+func fieldMatches(t *testing.T, value interface{}, found bool, cond interface{}) bool {
+	t.Helper()
+
+	ops, ok := cond.(bson.D)
+	if !ok || len(ops) == 0 || !strings.HasPrefix(ops[0].Key, "$") {
+		return equal(t, value, found, cond)
+	}
+
+	for _, op := range ops {
+		var m bool
+
+		switch op.Key {
+		case "$exists":
+			m = found == op.Value.(bool)
+		case "$ne":
+			m = !equal(t, value, found, op.Value)
+		case "$in":
+			for _, v := range op.Value.([]string) {
+				m = m || equal(t, value, found, v)
+			}
+		case "$gt", "$gte", "$lt", "$lte":
+			c, ok := compare(value, stored(t, op.Value))
+			if found && ok {
+				switch op.Key {
+				case "$gt":
+					m = c > 0
+				case "$gte":
+					m = c >= 0
+				case "$lt":
+					m = c < 0
+				case "$lte":
+					m = c <= 0
+				}
+			}
+		default:
+			t.Fatalf("unsupported operator %s", op.Key)
+		}
+
+		if !m {
+			return false
+		}
+	}
+
+	return true
+}
+
+// equal reports whether a field with value, if found, equals v. Null matches missing
+// fields as well.
+func equal(t *testing.T, value interface{}, found bool, v interface{}) bool {
+	t.Helper()
+
+	if v == nil {
+		return !found || value == nil
+	}
+
+	c, ok := compare(value, stored(t, v))
+
+	return found && ok && c == 0
+}
+
+// compare compares stored values of the same type.
+func compare(a, b interface{}) (int, bool) {
+	switch a := a.(type) {
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b), true
+		}
+	case int32:
+		return compareInts(int64(a), b)
+	case int64:
+		return compareInts(a, b)
+	case bool:
+		if b, ok := b.(bool); ok && a == b {
+			return 0, true
+		}
+	case primitive.DateTime:
+		if b, ok := b.(primitive.DateTime); ok {
+			return compareInts(int64(a), int64(b))
+		}
+	case primitive.ObjectID:
+		if b, ok := b.(primitive.ObjectID); ok {
+			return bytes.Compare(a[:], b[:]), true
+		}
+	}
+
+	return 0, false
+}
+
+func compareInts(a int64, b interface{}) (int, bool) {
+	var n int64
+
+	switch b := b.(type) {
+	case int32:
+		n = int64(b)
+	case int64:
+		n = b
+	default:
+		return 0, false
+	}
+
+	switch {
+	case a < n:
+		return -1, true
+	case a > n:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	"github.com/ernur-eskermes/product-store/pkg/filters"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PriceHistory struct {
+	db *mongo.Collection
+}
+
+func NewPriceHistory(db *mongo.Collection) *PriceHistory {
+	return &PriceHistory{
+		db: db,
+	}
+}
+
+func (r *PriceHistory) Create(ctx context.Context, changes []core.PriceChange) error {
+	docs := make([]interface{}, 0, len(changes))
+	for _, change := range changes {
+		docs = append(docs, change)
+	}
+
+	_, err := r.db.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+
+	return err
+}
+
+// EnsureIndexes creates the indexes the price history of a product is looked up by.
+func (r *PriceHistory) EnsureIndexes(ctx context.Context) error {
+	_, err := r.db.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "product_name", Value: 1}, {Key: "fetched_at", Value: 1}}},
+		{Keys: bson.D{{Key: "sku", Value: 1}, {Key: "fetched_at", Value: 1}}},
+	})
+
+	return err
+}
+
+// MigratePrices converts the old and new prices of changes recorded before prices had
+// a currency into amounts of currency, as Product.MigratePrices does.
+func (r *PriceHistory) MigratePrices(ctx context.Context, currency string) error {
+	for _, field := range []string{"old_price", "new_price"} {
+		_, err := r.db.UpdateMany(ctx, legacyPriceFilter(field), mongo.Pipeline{
+			{{Key: "$set", Value: bson.D{{Key: field, Value: legacyPrice("$"+field, currency)}}}},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *PriceHistory) GetAll(ctx context.Context, filter core.PriceHistoryFilter, f *filters.Filters) ([]core.PriceChange, error) {
+	opts := options.FindOptions{}
+	opts.SetSkip(f.Offset())
+	opts.SetLimit(f.Limit())
+	opts.SetSort(sortBy(f))
+
+	cur, err := r.db.Find(ctx, priceHistoryQuery(filter), &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]core.PriceChange, 0)
+	if err = cur.All(ctx, &changes); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+func (r *PriceHistory) GetTotalRecords(ctx context.Context, filter core.PriceHistoryFilter) (int64, error) {
+	return r.db.CountDocuments(ctx, priceHistoryQuery(filter))
+}
+
+func priceHistoryQuery(filter core.PriceHistoryFilter) bson.D {
+	query := bson.D{{Key: "product_name", Value: filter.ProductName}}
+	if filter.SKU != "" {
+		query = bson.D{{Key: "sku", Value: filter.SKU}}
+	}
+
+	fetchedAt := bson.D{}
+	if !filter.From.IsZero() {
+		fetchedAt = append(fetchedAt, bson.E{Key: "$gte", Value: filter.From})
+	}
+
+	if !filter.To.IsZero() {
+		fetchedAt = append(fetchedAt, bson.E{Key: "$lte", Value: filter.To})
+	}
+
+	if len(fetchedAt) != 0 {
+		query = append(query, bson.E{Key: "fetched_at", Value: fetchedAt})
+	}
+
+	return query
+}
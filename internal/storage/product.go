@@ -2,31 +2,60 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"regexp"
 
 	"github.com/ernur-eskermes/product-store/internal/core"
 	"github.com/ernur-eskermes/product-store/pkg/filters"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+const (
+	// suggestScanFactor bounds the products Suggest reads to this many per name asked
+	// for, which leaves room for products sharing a name.
+	suggestScanFactor = 5
+	// migrationBatchSize is the number of products migrations update at once.
+	migrationBatchSize = 1000
+)
+
 type Product struct {
-	db *mongo.Collection
+	db        *mongo.Collection
+	collation Collation
 }
 
-func NewProduct(db *mongo.Collection) *Product {
+func NewProduct(db *mongo.Collection, collation Collation) *Product {
 	return &Product{
-		db: db,
+		db:        db,
+		collation: collation,
 	}
 }
 
-func (r *Product) GetAll(ctx context.Context, f *filters.Filters) ([]core.Product, error) {
+// GetAll returns a page of the products filter lets through. The page starts right
+// after the cursor after if it is set, and at the offset of f otherwise. Strings are
+// sorted, and compared to the cursor, by the collation of r.
+func (r *Product) GetAll(ctx context.Context, filter core.ProductFilter, f *filters.Filters, after *core.ProductCursor) ([]core.Product, error) {
+	query := productListFilter(filter)
+
 	opts := options.FindOptions{}
-	opts.SetSkip(f.Offset())
 	opts.SetLimit(f.Limit())
-	opts.SetSort(bson.D{{Key: f.SortColumn(), Value: f.SortDirection()}})
+	opts.SetSort(productSort(f))
+	opts.SetCollation(r.collation.options())
+
+	if after != nil {
+		keyset, err := productAfterFilter(f, *after)
+		if err != nil {
+			return nil, err
+		}
+
+		query = bson.D{{Key: "$and", Value: bson.A{query, keyset}}}
+	} else {
+		opts.SetSkip(f.Offset())
+	}
 
-	cur, err := r.db.Find(ctx, bson.M{}, &opts)
+	cur, err := r.db.Find(ctx, query, &opts)
 	if err != nil {
 		return nil, err
 	}
@@ -39,22 +68,670 @@ func (r *Product) GetAll(ctx context.Context, f *filters.Filters) ([]core.Produc
 	return products, nil
 }
 
-func (r *Product) GetTotalRecords(ctx context.Context) (int64, error) {
-	return r.db.CountDocuments(ctx, bson.D{})
+func (r *Product) GetTotalRecords(ctx context.Context, filter core.ProductFilter) (int64, error) {
+	return r.db.CountDocuments(ctx, productListFilter(filter))
 }
 
-func (r *Product) UpdateOrCreate(ctx context.Context, products []core.Product) error {
+// Search returns a page of the products filter lets through that match the text
+// query, most relevant first, with their text score.
+func (r *Product) Search(ctx context.Context, query string, filter core.ProductFilter, f *filters.Filters) ([]core.SearchResult, error) {
+	score := bson.D{{Key: "$meta", Value: "textScore"}}
+
+	opts := options.FindOptions{}
+	opts.SetSkip(f.Offset())
+	opts.SetLimit(f.Limit())
+	opts.SetProjection(bson.D{{Key: "score", Value: score}})
+	opts.SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: filters.ASC}})
+
+	cur, err := r.db.Find(ctx, productSearchFilter(query, filter), &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []struct {
+		core.Product `bson:",inline"`
+		Score        float64 `bson:"score"`
+	}
+	if err = cur.All(ctx, &matches); err != nil {
+		return nil, err
+	}
+
+	results := make([]core.SearchResult, 0, len(matches))
+	for _, m := range matches {
+		results = append(results, core.SearchResult{Product: m.Product, Score: m.Score})
+	}
+
+	return results, nil
+}
+
+// GetSearchTotalRecords counts the products Search finds for query and filter.
+func (r *Product) GetSearchTotalRecords(ctx context.Context, query string, filter core.ProductFilter) (int64, error) {
+	return r.db.CountDocuments(ctx, productSearchFilter(query, filter))
+}
+
+// GetByID returns the product with the given id, or core.ErrProductNotFound.
+func (r *Product) GetByID(ctx context.Context, id primitive.ObjectID) (core.Product, error) {
+	var product core.Product
+	if err := r.db.FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&product); err != nil {
+		return core.Product{}, productError(err)
+	}
+
+	return product, nil
+}
+
+// GetBySKU returns the product with the given SKU, or core.ErrProductNotFound.
+func (r *Product) GetBySKU(ctx context.Context, sku string) (core.Product, error) {
+	var product core.Product
+	if err := r.db.FindOne(ctx, bson.D{{Key: "sku", Value: sku}}).Decode(&product); err != nil {
+		return core.Product{}, productError(err)
+	}
+
+	return product, nil
+}
+
+// UpdateOrCreate upserts products by their key: the SKU if they have one, the name
+// otherwise, so a product with a SKU keeps its identity when it is renamed. A product
+// with a SKU that is not stored yet takes over the product of the same name without a
+// SKU last stored from the source of run, so that a feed that starts sending SKUs
+// keeps its products and their price history. Every product is marked as seen by run,
+// which brings discontinued products back. See productWrites for what is counted.
+func (r *Product) UpdateOrCreate(ctx context.Context, products []core.Product, run core.FetchRun) (core.WriteResult, error) {
+	matches, err := r.match(ctx, run.SourceURL, products)
+	if err != nil {
+		return core.WriteResult{}, err
+	}
+
+	models, result := productWrites(products, matches, run)
+	if len(models) == 0 {
+		return result, nil
+	}
+
+	if _, err = r.db.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return core.WriteResult{}, err
+	}
+
+	return result, nil
+}
+
+// productWrites returns the write models storing each of products over its match, and
+// what each does: a product matching neither a stored product nor an earlier product of
+// the batch is inserted, and any other is repriced if its price differs from the last
+// one and unchanged otherwise, even if it was renamed.
+func productWrites(products, matches []core.Product, run core.FetchRun) ([]mongo.WriteModel, core.WriteResult) {
 	models := make([]mongo.WriteModel, 0, len(products))
+	result := core.WriteResult{Changes: make([]core.PriceChange, 0)}
+	prices := make(map[core.ProductKey]core.Money, len(products))
+
+	for i, product := range products {
+		filter := productFilter(product.Key())
+		if !matches[i].ID.IsZero() {
+			filter = bson.D{{Key: "_id", Value: matches[i].ID}}
+
+			if _, ok := prices[product.Key()]; !ok {
+				prices[product.Key()] = matches[i].Price
+			}
+		}
 
-	for _, product := range products {
 		models = append(models, mongo.NewUpdateOneModel().SetFilter(
-			bson.D{{Key: "name", Value: product.Name}},
+			filter,
 		).SetUpdate(
-			bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: product.Name}, {Key: "price", Value: product.Price}}}},
+			productUpdatePipeline(product, run),
 		).SetUpsert(true))
+
+		switch oldPrice, ok := prices[product.Key()]; {
+		case !ok:
+			result.Inserted++
+		case oldPrice != product.Price:
+			result.Repriced++
+			result.Changes = append(result.Changes, core.PriceChange{
+				ProductName: product.Name,
+				SKU:         product.SKU,
+				OldPrice:    oldPrice,
+				NewPrice:    product.Price,
+				FetchedAt:   run.StartedAt,
+			})
+		default:
+			result.Unchanged++
+		}
+
+		prices[product.Key()] = product.Price
+	}
+
+	return models, result
+}
+
+// GetPrices returns the stored price of every product in products that exists, by its
+// key, matching products as UpdateOrCreate does for a run from sourceURL.
+func (r *Product) GetPrices(ctx context.Context, sourceURL string, products []core.Product) (map[core.ProductKey]core.Money, error) {
+	matches, err := r.match(ctx, sourceURL, products)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[core.ProductKey]core.Money, len(products))
+	for i, product := range products {
+		if !matches[i].ID.IsZero() {
+			prices[product.Key()] = matches[i].Price
+		}
+	}
+
+	return prices, nil
+}
+
+// match returns the stored product each of products is saved over, see matchStored.
+func (r *Product) match(ctx context.Context, sourceURL string, products []core.Product) ([]core.Product, error) {
+	opts := options.Find().SetProjection(bson.D{
+		{Key: "sku", Value: 1}, {Key: "name", Value: 1}, {Key: "price", Value: 1}, {Key: "source_url", Value: 1},
+	})
+
+	cur, err := r.db.Find(ctx, storedProductsFilter(sourceURL, products), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make([]core.Product, 0, len(products))
+	if err = cur.All(ctx, &stored); err != nil {
+		return nil, err
+	}
+
+	return matchStored(sourceURL, products, stored), nil
+}
+
+// storedProductsFilter matches the stored products that products of a run from
+// sourceURL may be saved over: those with their SKUs, those without a SKU with the
+// names of products without one, and those of sourceURL without a SKU with the names
+// of products with one.
+func storedProductsFilter(sourceURL string, products []core.Product) bson.D {
+	skus := make([]string, 0)
+	names := make([]string, 0)
+	skuNames := make([]string, 0)
+
+	for _, product := range products {
+		if product.SKU != "" {
+			skus = append(skus, product.SKU)
+			skuNames = append(skuNames, product.Name)
+		} else {
+			names = append(names, product.Name)
+		}
+	}
+
+	noSKU := bson.E{Key: "sku", Value: bson.D{{Key: "$exists", Value: false}}}
+
+	return bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "sku", Value: bson.D{{Key: "$in", Value: skus}}}},
+		bson.D{{Key: "name", Value: bson.D{{Key: "$in", Value: names}}}, noSKU},
+		bson.D{{Key: "name", Value: bson.D{{Key: "$in", Value: skuNames}}}, noSKU, {Key: "source_url", Value: sourceURL}},
+	}}}
+}
+
+// matchStored returns, for each of products of a run from sourceURL, the product of
+// stored it is saved over, or a zero product if it is new. A product with a SKU is
+// matched by it, or else takes over a product without a SKU of the same name from
+// sourceURL that no other product of the batch took over. A product without a SKU is
+// matched by its name, preferring a product from sourceURL.
+func matchStored(sourceURL string, products, stored []core.Product) []core.Product {
+	bySKU := make(map[string]core.Product)
+	byName := make(map[string]core.Product)
+	adoptable := make(map[string]core.Product)
+
+	for _, p := range stored {
+		switch {
+		case p.SKU != "":
+			bySKU[p.SKU] = p
+		case p.SourceURL == sourceURL:
+			byName[p.Name] = p
+			adoptable[p.Name] = p
+		default:
+			if _, ok := byName[p.Name]; !ok {
+				byName[p.Name] = p
+			}
+		}
+	}
+
+	adopted := make(map[primitive.ObjectID]bool)
+	matches := make([]core.Product, len(products))
+
+	for i, product := range products {
+		if product.SKU == "" {
+			continue
+		}
+
+		if p, ok := bySKU[product.SKU]; ok {
+			matches[i] = p
+		} else if p, ok := adoptable[product.Name]; ok && !adopted[p.ID] {
+			matches[i] = p
+			adopted[p.ID] = true
+		}
+	}
+
+	for i, product := range products {
+		if p, ok := byName[product.Name]; ok && product.SKU == "" && !adopted[p.ID] {
+			matches[i] = p
+		}
+	}
+
+	return matches
+}
+
+// Suggest returns up to limit distinct names of products that are not discontinued
+// whose name key, see core.NameKey, starts with prefix, in name key order. It reads the
+// name key index from prefix on and stops after a bounded number of products, so it
+// answers quickly even when many products share a name.
+func (r *Product) Suggest(ctx context.Context, prefix string, limit int) ([]string, error) {
+	filter := append(bson.D{
+		{Key: "name_key", Value: primitive.Regex{Pattern: "^" + regexp.QuoteMeta(prefix)}},
+	}, productListFilter(core.ProductFilter{})...)
+
+	opts := options.Find().
+		SetProjection(bson.D{{Key: "name", Value: 1}}).
+		SetSort(bson.D{{Key: "name_key", Value: 1}}).
+		SetLimit(int64(limit * suggestScanFactor))
+
+	cur, err := r.db.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	names := make([]string, 0, limit)
+	seen := make(map[string]bool, limit)
+
+	for len(names) < limit && cur.Next(ctx) {
+		var product core.Product
+		if err = cur.Decode(&product); err != nil {
+			return nil, err
+		}
+
+		if !seen[product.Name] {
+			seen[product.Name] = true
+			names = append(names, product.Name)
+		}
+	}
+
+	return names, cur.Err()
+}
+
+// MigrateNameKeys sets the name key of products stored before products had one.
+func (r *Product) MigrateNameKeys(ctx context.Context) error {
+	cur, err := r.db.Find(ctx, bson.D{{Key: "name_key", Value: bson.D{{Key: "$exists", Value: false}}}},
+		options.Find().SetProjection(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	models := make([]mongo.WriteModel, 0, migrationBatchSize)
+
+	flush := func() error {
+		if len(models) == 0 {
+			return nil
+		}
+
+		_, err := r.db.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+		models = models[:0]
+
+		return err
 	}
 
-	_, err := r.db.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	for cur.Next(ctx) {
+		var product core.Product
+		if err = cur.Decode(&product); err != nil {
+			return err
+		}
+
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.D{{Key: "_id", Value: product.ID}}).
+			SetUpdate(bson.D{{Key: "$set", Value: bson.D{{Key: "name_key", Value: core.NameKey(product.Name)}}}}))
+
+		if len(models) == migrationBatchSize {
+			if err = flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err = cur.Err(); err != nil {
+		return err
+	}
+
+	return flush()
+}
+
+// MigrateChangeTracking sets updated_at and price_change_count on products stored
+// before products had them, so that they sort like any other product: updated when
+// they were created, with no price changes.
+func (r *Product) MigrateChangeTracking(ctx context.Context) error {
+	_, err := r.db.UpdateMany(ctx, untrackedProductFilter(), mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{
+			{Key: "updated_at", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$updated_at", bson.D{{Key: "$toDate", Value: "$_id"}}}}}},
+			{Key: "price_change_count", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$price_change_count", 0}}}},
+		}}},
+	})
+
+	return err
+}
+
+// Discontinue marks the products last stored from the source of run by an earlier
+// run as discontinued at the start of run, and returns how many there were.
+func (r *Product) Discontinue(ctx context.Context, run core.FetchRun) (int, error) {
+	res, err := r.db.UpdateMany(ctx, bson.D{
+		{Key: "source_url", Value: run.SourceURL},
+		{Key: "last_run_id", Value: bson.D{{Key: "$ne", Value: run.ID}}},
+		{Key: "discontinued_at", Value: bson.D{{Key: "$exists", Value: false}}},
+	}, bson.D{{Key: "$set", Value: bson.D{{Key: "discontinued_at", Value: run.StartedAt}}}})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(res.ModifiedCount), nil
+}
+
+// Scan calls fn with the SKU, name and price of every product last stored from
+// sourceURL that is not discontinued, in name order, until fn returns false.
+func (r *Product) Scan(ctx context.Context, sourceURL string, fn func(core.Product) bool) error {
+	opts := options.Find().
+		SetProjection(bson.D{{Key: "sku", Value: 1}, {Key: "name", Value: 1}, {Key: "price", Value: 1}}).
+		SetSort(bson.D{{Key: "name", Value: 1}}).
+		SetAllowDiskUse(true)
+
+	cur, err := r.db.Find(ctx, productSourceFilter(sourceURL), opts)
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var product core.Product
+		if err = cur.Decode(&product); err != nil {
+			return err
+		}
+
+		if !fn(product) {
+			return nil
+		}
+	}
+
+	return cur.Err()
+}
+
+// EnsureIndexes creates the indexes products are looked up by. SKUs are unique, but
+// products without one are left out of that index, so any number of them may exist.
+// Names and SKUs are text indexed for Search, a name match weighing more, and indexed
+// with the collation of r for listing products sorted by them.
+func (r *Product) EnsureIndexes(ctx context.Context) error {
+	_, err := r.db.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "sku", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(
+				bson.D{{Key: "sku", Value: bson.D{{Key: "$type", Value: "string"}}}},
+			),
+		},
+		{Keys: bson.D{{Key: "name", Value: 1}}},
+		{Keys: bson.D{{Key: "name_key", Value: 1}}},
+		{Keys: bson.D{{Key: "source_url", Value: 1}, {Key: "last_run_id", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "name", Value: "text"}, {Key: "sku", Value: "text"}},
+			Options: options.Index().SetName("products_text").SetWeights(bson.D{{Key: "name", Value: 10}, {Key: "sku", Value: 5}}),
+		},
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}},
+			Options: options.Index().SetName(r.collation.indexName("name")).SetCollation(r.collation.options()),
+		},
+		{
+			Keys:    bson.D{{Key: "sku", Value: 1}, {Key: "_id", Value: 1}},
+			Options: options.Index().SetName(r.collation.indexName("sku")).SetCollation(r.collation.options()),
+		},
+	})
+
+	return err
+}
+
+// MigratePrices converts prices stored as bare numbers, from before prices had a
+// currency, into amounts of currency. Those prices were whole units, so they are
+// scaled to the minor unit of currency. Products already migrated are left alone.
+func (r *Product) MigratePrices(ctx context.Context, currency string) error {
+	_, err := r.db.UpdateMany(ctx, legacyPriceFilter("price"), mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{{Key: "price", Value: legacyPrice("$price", currency)}}}},
+	})
+
+	return err
+}
+
+// legacyPriceFilter matches documents whose field is a bare number.
+func legacyPriceFilter(field string) bson.D {
+	return bson.D{{Key: field, Value: bson.D{{Key: "$type", Value: "number"}}}}
+}
+
+// legacyPrice is an aggregation expression turning the whole-unit number at path into
+// a core.Money of currency.
+func legacyPrice(path, currency string) bson.D {
+	scale := int64(1)
+	for i := 0; i < core.CurrencyExponent(currency); i++ {
+		scale *= 10
+	}
+
+	return bson.D{
+		{Key: "amount", Value: bson.D{{Key: "$toLong", Value: bson.D{{Key: "$multiply", Value: bson.A{path, scale}}}}}},
+		{Key: "currency", Value: bson.D{{Key: "$literal", Value: currency}}},
+	}
+}
+
+// productSortFields returns the fields products are sorted on for column, matching
+// core.Product.SortKey. Prices only compare within a currency, so products are sorted
+// by currency first and by amount within it.
+func productSortFields(column string) []string {
+	if column == "price" {
+		return []string{"price.currency", "price.amount"}
+	}
+
+	return []string{column}
+}
+
+// productSort orders products by the sort keys of f, and then by id in the direction
+// of the last key, so that products with the same sort key keep their order from page
+// to page.
+func productSort(f *filters.Filters) bson.D {
+	sort := bson.D{}
+	direction := filters.ASC
+
+	for _, key := range f.SortKeys() {
+		for _, field := range productSortFields(key.Column) {
+			sort = append(sort, bson.E{Key: field, Value: key.Direction})
+		}
+
+		direction = key.Direction
+	}
+
+	return append(sort, bson.E{Key: "_id", Value: direction})
+}
+
+// productAfterFilter matches the products that come after the cursor in the order of
+// productSort: those with a later value of some sort field and the same values of the
+// fields before it, and those with the same sort key and a later id. Missing values
+// sort before any other, and comparison operators never match them, so they are
+// handled on their own.
+func productAfterFilter(f *filters.Filters, after core.ProductCursor) (bson.D, error) {
+	sort := productSort(f)
+	if len(after.Key) != len(sort)-1 {
+		return nil, core.ErrInvalidPageToken
+	}
+
+	terms := bson.A{}
+	equal := bson.D{}
+
+	for i, e := range sort {
+		op := "$gt"
+		if e.Value == filters.DESC {
+			op = "$lt"
+		}
+
+		if e.Key == "_id" {
+			terms = append(terms, extend(equal, bson.E{Key: "_id", Value: bson.D{{Key: op, Value: after.ID}}}))
+
+			break
+		}
+
+		if term := keyAfter(e.Key, after.Key[i], op); term != nil {
+			terms = append(terms, extend(equal, term...))
+		}
+
+		equal = append(equal, bson.E{Key: e.Key, Value: after.Key[i]})
+	}
+
+	return bson.D{{Key: "$or", Value: terms}}, nil
+}
+
+// keyAfter matches the values of field that come after value when sorted by op, or
+// returns nil if none do.
+func keyAfter(field string, value interface{}, op string) bson.D {
+	switch {
+	case value == nil && op == "$lt":
+		return nil
+	case value == nil:
+		return bson.D{{Key: field, Value: bson.D{{Key: "$ne", Value: nil}}}}
+	case op == "$lt":
+		return bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: field, Value: bson.D{{Key: op, Value: value}}}},
+			bson.D{{Key: field, Value: nil}},
+		}}}
+	default:
+		return bson.D{{Key: field, Value: bson.D{{Key: op, Value: value}}}}
+	}
+}
+
+// extend returns a copy of d with elems appended.
+func extend(d bson.D, elems ...bson.E) bson.D {
+	res := make(bson.D, 0, len(d)+len(elems))
+
+	return append(append(res, d...), elems...)
+}
+
+// productFilter matches the stored product with the given key, for products that
+// match no stored product yet.
+func productFilter(key core.ProductKey) bson.D {
+	if key.SKU != "" {
+		return bson.D{{Key: "sku", Value: key.SKU}}
+	}
+
+	return bson.D{{Key: "name", Value: key.Name}, {Key: "sku", Value: bson.D{{Key: "$exists", Value: false}}}}
+}
+
+// untrackedProductFilter matches the products without an update time or a price
+// change count, or with either set to null.
+func untrackedProductFilter() bson.D {
+	return bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "updated_at", Value: nil}},
+		bson.D{{Key: "price_change_count", Value: nil}},
+	}}}
+}
+
+// productSourceFilter matches the products last stored from sourceURL that are not
+// discontinued.
+func productSourceFilter(sourceURL string) bson.D {
+	return append(bson.D{{Key: "source_url", Value: sourceURL}}, productListFilter(core.ProductFilter{})...)
+}
+
+// productSearchFilter matches the products filter lets through that match the text
+// query.
+func productSearchFilter(query string, filter core.ProductFilter) bson.D {
+	return append(bson.D{{Key: "$text", Value: bson.D{{Key: "$search", Value: query}}}}, productListFilter(filter)...)
+}
+
+// productListFilter matches the products filter lets through.
+func productListFilter(filter core.ProductFilter) bson.D {
+	query := bson.D{}
+
+	if !filter.IncludeDiscontinued {
+		query = append(query, bson.E{Key: "discontinued_at", Value: bson.D{{Key: "$exists", Value: false}}})
+	}
+
+	// Both bounds are in the same currency, which products must be priced in.
+	amount := bson.D{}
+	currency := ""
+
+	if filter.MinPrice != nil {
+		amount = append(amount, bson.E{Key: "$gte", Value: filter.MinPrice.Amount})
+		currency = filter.MinPrice.Currency
+	}
+
+	if filter.MaxPrice != nil {
+		amount = append(amount, bson.E{Key: "$lte", Value: filter.MaxPrice.Amount})
+		currency = filter.MaxPrice.Currency
+	}
+
+	if len(amount) != 0 {
+		query = append(query, bson.E{Key: "price.currency", Value: currency}, bson.E{Key: "price.amount", Value: amount})
+	}
+
+	var names bson.A
+
+	// The prefix is matched on name_key rather than name: an anchored, case-sensitive
+	// regex on it is served by its index.
+	if filter.NamePrefix != "" {
+		names = append(names, bson.D{{Key: "name_key", Value: primitive.Regex{Pattern: "^" + regexp.QuoteMeta(core.NameKey(filter.NamePrefix))}}})
+	}
+
+	if filter.NameContains != "" {
+		names = append(names, bson.D{{Key: "name", Value: primitive.Regex{Pattern: regexp.QuoteMeta(filter.NameContains), Options: "i"}}})
+	}
+
+	// The name may be matched twice, so the conditions on it are joined with $and.
+	if len(names) != 0 {
+		query = append(query, bson.E{Key: "$and", Value: names})
+	}
+
+	return query
+}
+
+func productError(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return core.ErrProductNotFound
+	}
 
 	return err
 }
+
+// productUpdatePipeline builds an update pipeline that bumps price_change_count and
+// updated_at only when the stored price differs from the incoming one, or sets them if
+// the product has none yet, records run as the last one to see the product and clears
+// discontinued_at. Expressions inside a single $set stage see the document as it was
+// before the update, so "$price" below is always the old price. Prices are compared as
+// a whole, so a change of currency is a change of price.
+func productUpdatePipeline(product core.Product, run core.FetchRun) mongo.Pipeline {
+	price := bson.D{{Key: "$literal", Value: product.Price}}
+	isNew := bson.D{{Key: "$eq", Value: bson.A{bson.D{{Key: "$type", Value: "$price"}}, "missing"}}}
+	priceChanged := bson.D{{Key: "$ne", Value: bson.A{"$price", price}}}
+	changeCount := bson.D{{Key: "$ifNull", Value: bson.A{"$price_change_count", 0}}}
+
+	set := bson.D{
+		{Key: "name", Value: bson.D{{Key: "$literal", Value: product.Name}}},
+		{Key: "name_key", Value: bson.D{{Key: "$literal", Value: core.NameKey(product.Name)}}},
+		{Key: "price", Value: price},
+		{Key: "price_change_count", Value: bson.D{{Key: "$cond", Value: bson.A{
+			isNew,
+			0,
+			bson.D{{Key: "$cond", Value: bson.A{
+				priceChanged,
+				bson.D{{Key: "$add", Value: bson.A{changeCount, 1}}},
+				changeCount,
+			}}},
+		}}}},
+		{Key: "updated_at", Value: bson.D{{Key: "$cond", Value: bson.A{
+			priceChanged,
+			run.StartedAt,
+			bson.D{{Key: "$ifNull", Value: bson.A{"$updated_at", run.StartedAt}}},
+		}}}},
+		{Key: "source_url", Value: bson.D{{Key: "$literal", Value: run.SourceURL}}},
+		{Key: "last_run_id", Value: run.ID},
+		{Key: "last_seen_at", Value: run.StartedAt},
+	}
+
+	if product.SKU != "" {
+		set = append(set, bson.E{Key: "sku", Value: bson.D{{Key: "$literal", Value: product.SKU}}})
+	}
+
+	return mongo.Pipeline{
+		{{Key: "$set", Value: set}},
+		{{Key: "$unset", Value: "discontinued_at"}},
+	}
+}
@@ -0,0 +1,396 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	"github.com/ernur-eskermes/product-store/pkg/filters"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestProduct_SourceFilter(t *testing.T) {
+	discontinuedAt := time.Date(2022, 7, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		product core.Product
+		matched bool
+	}{
+		{
+			name:    "same_source",
+			product: core.Product{Name: "Apple", SourceURL: "https://some-url.com/feed.csv"},
+			matched: true,
+		},
+		{
+			name:    "other_source",
+			product: core.Product{Name: "Apple", SourceURL: "https://other-url.com/feed.csv"},
+		},
+		{
+			name:    "no_source",
+			product: core.Product{Name: "Apple"},
+		},
+		{
+			name:    "discontinued",
+			product: core.Product{Name: "Apple", SourceURL: "https://some-url.com/feed.csv", DiscontinuedAt: discontinuedAt},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.matched, matches(t, productSourceFilter("https://some-url.com/feed.csv"), document(t, tt.product)))
+		})
+	}
+}
+
+func TestProduct_UntrackedFilter(t *testing.T) {
+	product := core.Product{
+		ID:        primitive.NewObjectID(),
+		Name:      "Apple",
+		Price:     core.Money{Amount: 100, Currency: "USD"},
+		UpdatedAt: time.Date(2022, 7, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name    string
+		unset   []string
+		null    []string
+		matched bool
+	}{
+		{
+			name: "tracked",
+		},
+		{
+			name:    "legacy",
+			unset:   []string{"updated_at", "price_change_count"},
+			matched: true,
+		},
+		{
+			name:    "no_updated_at",
+			unset:   []string{"updated_at"},
+			matched: true,
+		},
+		{
+			name:    "null_price_change_count",
+			null:    []string{"price_change_count"},
+			matched: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := document(t, product)
+			for _, field := range tt.unset {
+				delete(doc, field)
+			}
+
+			for _, field := range tt.null {
+				doc[field] = nil
+			}
+
+			require.Equal(t, tt.matched, matches(t, untrackedProductFilter(), doc))
+		})
+	}
+}
+
+func TestProduct_MatchStored(t *testing.T) {
+	source := "https://some-url.com/feed.csv"
+
+	apple := core.Product{ID: primitive.NewObjectID(), Name: "Apple", SourceURL: source}
+	otherApple := core.Product{ID: primitive.NewObjectID(), Name: "Apple", SourceURL: "https://other-url.com/feed.csv"}
+	skuApple := core.Product{ID: primitive.NewObjectID(), SKU: "A-1", Name: "Old Apple", SourceURL: source}
+
+	tests := []struct {
+		name       string
+		products   []core.Product
+		stored     []core.Product
+		expMatches []core.Product
+	}{
+		{
+			name:       "name_to_sku",
+			products:   []core.Product{{SKU: "A-1", Name: "Apple"}},
+			stored:     []core.Product{apple},
+			expMatches: []core.Product{apple},
+		},
+		{
+			name:       "sku_stored",
+			products:   []core.Product{{SKU: "A-1", Name: "Apple"}},
+			stored:     []core.Product{apple, skuApple},
+			expMatches: []core.Product{skuApple},
+		},
+		{
+			name:       "other_source_not_taken_over",
+			products:   []core.Product{{SKU: "A-1", Name: "Apple"}},
+			stored:     []core.Product{otherApple},
+			expMatches: []core.Product{{}},
+		},
+		{
+			name:       "taken_over_once",
+			products:   []core.Product{{SKU: "A-1", Name: "Apple"}, {SKU: "A-2", Name: "Apple"}},
+			stored:     []core.Product{apple},
+			expMatches: []core.Product{apple, {}},
+		},
+		{
+			name:       "name_after_take_over",
+			products:   []core.Product{{Name: "Apple"}, {SKU: "A-1", Name: "Apple"}},
+			stored:     []core.Product{apple},
+			expMatches: []core.Product{{}, apple},
+		},
+		{
+			name:       "name_prefers_source",
+			products:   []core.Product{{Name: "Apple"}},
+			stored:     []core.Product{otherApple, apple},
+			expMatches: []core.Product{apple},
+		},
+		{
+			name:       "name_from_other_source",
+			products:   []core.Product{{Name: "Apple"}},
+			stored:     []core.Product{otherApple},
+			expMatches: []core.Product{otherApple},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expMatches, matchStored(source, tt.products, tt.stored))
+		})
+	}
+}
+
+func TestProduct_Writes(t *testing.T) {
+	usd := func(amount int64) core.Money { return core.Money{Amount: amount, Currency: "USD"} }
+	apple := core.Product{ID: primitive.NewObjectID(), SKU: "A-1", Name: "Apple", Price: usd(100)}
+	run := core.FetchRun{ID: primitive.NewObjectID(), StartedAt: time.Date(2022, 7, 1, 10, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name       string
+		products   []core.Product
+		matches    []core.Product
+		expResult  core.WriteResult
+		expChanges int
+	}{
+		{
+			name:      "inserted",
+			products:  []core.Product{{SKU: "A-1", Name: "Apple", Price: usd(100)}},
+			matches:   []core.Product{{}},
+			expResult: core.WriteResult{Inserted: 1},
+		},
+		{
+			name:      "unchanged",
+			products:  []core.Product{{SKU: "A-1", Name: "Green Apple", Price: usd(100)}},
+			matches:   []core.Product{apple},
+			expResult: core.WriteResult{Unchanged: 1},
+		},
+		{
+			name:       "repriced",
+			products:   []core.Product{{SKU: "A-1", Name: "Apple", Price: usd(120)}},
+			matches:    []core.Product{apple},
+			expResult:  core.WriteResult{Repriced: 1},
+			expChanges: 1,
+		},
+		{
+			name:       "repeated_stored_key",
+			products:   []core.Product{{SKU: "A-1", Name: "Apple", Price: usd(100)}, {SKU: "A-1", Name: "Apple", Price: usd(120)}, {SKU: "A-1", Name: "Apple", Price: usd(120)}},
+			matches:    []core.Product{apple, apple, apple},
+			expResult:  core.WriteResult{Repriced: 1, Unchanged: 2},
+			expChanges: 1,
+		},
+		{
+			name:       "repeated_new_key",
+			products:   []core.Product{{Name: "Pear", Price: usd(100)}, {Name: "Pear", Price: usd(100)}, {Name: "Pear", Price: usd(90)}},
+			matches:    []core.Product{{}, {}, {}},
+			expResult:  core.WriteResult{Inserted: 1, Repriced: 1, Unchanged: 1},
+			expChanges: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			models, result := productWrites(tt.products, tt.matches, run)
+
+			require.Len(t, models, len(tt.products))
+			require.Len(t, result.Changes, tt.expChanges)
+
+			result.Changes = nil
+			require.Equal(t, tt.expResult, result)
+		})
+	}
+}
+
+func TestProduct_StoredProductsFilter(t *testing.T) {
+	source := "https://some-url.com/feed.csv"
+	filter := storedProductsFilter(source, []core.Product{{SKU: "A-1", Name: "Apple"}, {Name: "Pear"}})
+
+	tests := []struct {
+		name    string
+		product core.Product
+		matched bool
+	}{
+		{
+			name:    "same_sku",
+			product: core.Product{SKU: "A-1", Name: "Green Apple", SourceURL: "https://other-url.com/feed.csv"},
+			matched: true,
+		},
+		{
+			name:    "name_without_sku_from_source",
+			product: core.Product{Name: "Apple", SourceURL: source},
+			matched: true,
+		},
+		{
+			name:    "name_without_sku_from_other_source",
+			product: core.Product{Name: "Apple", SourceURL: "https://other-url.com/feed.csv"},
+		},
+		{
+			name:    "name_with_other_sku",
+			product: core.Product{SKU: "A-2", Name: "Apple", SourceURL: source},
+		},
+		{
+			name:    "name_of_product_without_sku",
+			product: core.Product{Name: "Pear", SourceURL: "https://other-url.com/feed.csv"},
+			matched: true,
+		},
+		{
+			name:    "name_of_product_without_sku_with_sku",
+			product: core.Product{SKU: "P-1", Name: "Pear", SourceURL: source},
+		},
+		{
+			name:    "other_name",
+			product: core.Product{Name: "Plum", SourceURL: source},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.matched, matches(t, filter, document(t, tt.product)))
+		})
+	}
+}
+
+func TestProduct_AfterFilter(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2022, 7, d, 10, 0, 0, 0, time.UTC) }
+	id := func(n byte) primitive.ObjectID { return primitive.ObjectID{11: n} }
+
+	// Products 3 and 4 tie on every sort key but their id. Product 6 has its SKU stored
+	// as null rather than left out.
+	products := []core.Product{
+		{ID: id(1), SKU: "B-1", Name: "Pear", Price: core.Money{Amount: 200, Currency: "USD"}, UpdatedAt: day(2), PriceChangeCount: 1},
+		{ID: id(2), SKU: "A-1", Name: "Apple", Price: core.Money{Amount: 100, Currency: "USD"}, UpdatedAt: day(1), PriceChangeCount: 3},
+		{ID: id(3), Name: "Kiwi", Price: core.Money{Amount: 100, Currency: "EUR"}, UpdatedAt: day(3), PriceChangeCount: 1},
+		{ID: id(4), Name: "Kiwi", Price: core.Money{Amount: 100, Currency: "EUR"}, UpdatedAt: day(3), PriceChangeCount: 1},
+		{ID: id(5), SKU: "C-1", Name: "Apple", Price: core.Money{Amount: 300, Currency: "USD"}, UpdatedAt: day(2)},
+		{ID: id(6), Name: "Plum", Price: core.Money{Amount: 200, Currency: "USD"}, UpdatedAt: day(1), PriceChangeCount: 3},
+	}
+	nullSKU := id(6)
+
+	sorts := []string{
+		"name", "-name",
+		"sku", "-sku",
+		"price", "-price",
+		"updated_at", "-updated_at",
+		"price_change_count", "-price_change_count",
+		"name,sku", "-name,sku", "name,-sku",
+		"sku,-updated_at,name",
+		"-price,name", "price,-price_change_count,-sku",
+	}
+
+	for _, sortBy := range sorts {
+		t.Run(sortBy, func(t *testing.T) {
+			f := filters.New(1, 10, sortBy, core.ProductDefaultSort, core.ProductSortSafeList)
+			sorted := sortProducts(f, products)
+
+			for i, after := range sorted {
+				filter, err := productAfterFilter(f, core.NewProductCursor(after, sortBy))
+				require.NoError(t, err)
+
+				var got []primitive.ObjectID
+
+				for _, product := range sorted {
+					doc := document(t, product)
+					if product.ID == nullSKU {
+						doc["sku"] = nil
+					}
+
+					if matches(t, filter, doc) {
+						got = append(got, product.ID)
+					}
+				}
+
+				var exp []primitive.ObjectID
+				for _, product := range sorted[i+1:] {
+					exp = append(exp, product.ID)
+				}
+
+				require.Equal(t, exp, got, "after product %s", after.ID.Hex())
+			}
+		})
+	}
+}
+
+func TestProduct_AfterFilterInvalidCursor(t *testing.T) {
+	f := filters.New(1, 10, "price,name", core.ProductDefaultSort, core.ProductSortSafeList)
+
+	_, err := productAfterFilter(f, core.NewProductCursor(core.Product{Name: "Apple"}, "name"))
+	require.ErrorIs(t, err, core.ErrInvalidPageToken)
+}
+
+// sortProducts sorts products the way productSort orders them: by each sort key of f in
+// turn, missing values first, and then by id in the direction of the last key.
+func sortProducts(f *filters.Filters, products []core.Product) []core.Product {
+	sorted := append([]core.Product(nil), products...)
+	keys := f.SortKeys()
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		direction := filters.ASC
+
+		for _, key := range keys {
+			a, b := sorted[i].SortKey(key.Column), sorted[j].SortKey(key.Column)
+
+			for k := range a {
+				if c := compareSortValues(a[k], b[k]); c != 0 {
+					return c*key.Direction < 0
+				}
+			}
+
+			direction = key.Direction
+		}
+
+		return bytes.Compare(sorted[i].ID[:], sorted[j].ID[:])*direction < 0
+	})
+
+	return sorted
+}
+
+func compareSortValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case int64:
+		c, _ := compareInts(a, b)
+
+		return c
+	case int:
+		c, _ := compareInts(int64(a), int64(b.(int)))
+
+		return c
+	case time.Time:
+		c, _ := compareInts(a.UnixNano(), b.(time.Time).UnixNano())
+
+		return c
+	default:
+		panic(fmt.Sprintf("unexpected sort value %T", a))
+	}
+}
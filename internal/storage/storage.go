@@ -1,15 +1,102 @@
 package storage
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/ernur-eskermes/product-store/pkg/filters"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type Storage struct {
-	Product *Product
+	Product      *Product
+	PriceHistory *PriceHistory
+	FetchJob     *FetchJob
+	FeedSource   *FeedSource
+	FeedCache    *FeedCache
+}
+
+// Collation is how product names and SKUs are compared when products are listed:
+// by the rules of Locale, an ICU locale such as "en" or "de", ignoring case unless
+// CaseSensitive is set.
+type Collation struct {
+	Locale        string
+	CaseSensitive bool
+}
+
+func (c Collation) options() *options.Collation {
+	strength := 2
+	if c.CaseSensitive {
+		strength = 3
+	}
+
+	return &options.Collation{Locale: c.Locale, Strength: strength}
 }
 
-func New(db *mongo.Database) *Storage {
+// indexName names the index of field with collation c. The name tells the collation
+// apart, so that changing it creates a new index rather than failing on the old one.
+func (c Collation) indexName(field string) string {
+	o := c.options()
+
+	return fmt.Sprintf("%s_%s_%d", field, o.Locale, o.Strength)
+}
+
+func New(db *mongo.Database, collation Collation) *Storage {
 	return &Storage{
-		Product: NewProduct(db.Collection("products")),
+		Product:      NewProduct(db.Collection("products"), collation),
+		PriceHistory: NewPriceHistory(db.Collection("price_history")),
+		FetchJob:     NewFetchJob(db.Collection("fetch_jobs")),
+		FeedSource:   NewFeedSource(db.Collection("feed_sources")),
+		FeedCache:    NewFeedCache(db.Collection("feed_cache")),
+	}
+}
+
+// EnsureIndexes creates the indexes the storages rely on. Existing indexes are kept.
+func (s *Storage) EnsureIndexes(ctx context.Context) error {
+	if err := s.Product.EnsureIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := s.PriceHistory.EnsureIndexes(ctx); err != nil {
+		return err
 	}
+
+	if err := s.FetchJob.EnsureIndexes(ctx); err != nil {
+		return err
+	}
+
+	return s.FeedSource.EnsureIndexes(ctx)
+}
+
+// MigratePrices converts prices stored before prices had a currency into amounts of
+// currency, the currency feeds were priced in.
+func (s *Storage) MigratePrices(ctx context.Context, currency string) error {
+	if err := s.Product.MigratePrices(ctx, currency); err != nil {
+		return err
+	}
+
+	return s.PriceHistory.MigratePrices(ctx, currency)
+}
+
+// MigrateNameKeys sets the name key of products stored before products had one.
+func (s *Storage) MigrateNameKeys(ctx context.Context) error {
+	return s.Product.MigrateNameKeys(ctx)
+}
+
+// MigrateChangeTracking sets the update time and price change count of products
+// stored before products had them.
+func (s *Storage) MigrateChangeTracking(ctx context.Context) error {
+	return s.Product.MigrateChangeTracking(ctx)
+}
+
+// sortBy orders documents by the sort keys of f.
+func sortBy(f *filters.Filters) bson.D {
+	sort := bson.D{}
+	for _, key := range f.SortKeys() {
+		sort = append(sort, bson.E{Key: key.Column, Value: key.Direction})
+	}
+
+	return sort
 }
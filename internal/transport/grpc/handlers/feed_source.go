@@ -0,0 +1,239 @@
+package grpcHandler
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	pb "github.com/ernur-eskermes/product-store/pkg/domain"
+	"github.com/ernur-eskermes/product-store/pkg/filters"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type FeedSourceService interface {
+	Create(ctx context.Context, source core.FeedSource) (core.FeedSource, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (core.FeedSource, error)
+	GetAll(ctx context.Context, f *filters.Filters) ([]core.FeedSource, error)
+	GetTotalRecords(ctx context.Context) (int64, error)
+	Update(ctx context.Context, source core.FeedSource) (core.FeedSource, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+func (h *ProductHandler) CreateFeedSource(ctx context.Context, req *pb.FeedSource) (*pb.FeedSource, error) {
+	source := feedSourceFromPB(req)
+	if err := validateFeedSource(source); err != nil {
+		return nil, ErrorFilterResponse(err)
+	}
+
+	source, err := h.feedSourceService.Create(ctx, source)
+	if err != nil {
+		return nil, feedSourceErrorResponse(err)
+	}
+
+	return feedSourceToPB(source), nil
+}
+
+func (h *ProductHandler) GetFeedSource(ctx context.Context, req *pb.GetFeedSourceRequest) (*pb.FeedSource, error) {
+	id, err := primitive.ObjectIDFromHex(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	source, err := h.feedSourceService.GetByID(ctx, id)
+	if err != nil {
+		return nil, feedSourceErrorResponse(err)
+	}
+
+	return feedSourceToPB(source), nil
+}
+
+func (h *ProductHandler) ListFeedSources(ctx context.Context, req *pb.ListFeedSourcesRequest) (*pb.ListFeedSourcesResponse, error) {
+	f := filters.New(
+		req.Page,
+		req.PageSize,
+		req.Sort,
+		core.FeedSourceDefaultSort,
+		core.FeedSourceSortSafeList,
+	)
+
+	if err := filters.ValidateFilters(f); err != nil {
+		return nil, ErrorFilterResponse(err)
+	}
+
+	sources, err := h.feedSourceService.GetAll(ctx, f)
+	if err != nil {
+		return nil, status.Error(codes.Unknown, err.Error())
+	}
+
+	totalRecords, err := h.feedSourceService.GetTotalRecords(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unknown, err.Error())
+	}
+
+	res := make([]*pb.FeedSource, 0, len(sources))
+
+	for _, source := range sources {
+		res = append(res, feedSourceToPB(source))
+	}
+
+	return &pb.ListFeedSourcesResponse{
+		Results:  res,
+		Metadata: calculateMetadata(totalRecords, f.Page, f.PageSize),
+	}, nil
+}
+
+func (h *ProductHandler) UpdateFeedSource(ctx context.Context, req *pb.FeedSource) (*pb.FeedSource, error) {
+	id, err := primitive.ObjectIDFromHex(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	source := feedSourceFromPB(req)
+	source.ID = id
+
+	// Sources are read with their secret values left out, so those sent back unchanged
+	// are restored from the stored source, as long as it is still fetched from the same
+	// place.
+	if source.Options.Auth != nil {
+		stored, err := h.feedSourceService.GetByID(ctx, id)
+		if err != nil {
+			return nil, feedSourceErrorResponse(err)
+		}
+
+		storedAuth := stored.Options.Auth
+		if !sameOrigin(stored.URL, source.URL) {
+			storedAuth = nil
+		}
+
+		auth, err := source.Options.Auth.Unredact(storedAuth)
+		if err != nil {
+			return nil, ErrorFilterResponse(filters.ValidationErrors{{Field: "auth", Message: err.Error()}})
+		}
+
+		source.Options.Auth = &auth
+	}
+
+	if err = validateFeedSource(source); err != nil {
+		return nil, ErrorFilterResponse(err)
+	}
+
+	source, err = h.feedSourceService.Update(ctx, source)
+	if err != nil {
+		return nil, feedSourceErrorResponse(err)
+	}
+
+	return feedSourceToPB(source), nil
+}
+
+func (h *ProductHandler) DeleteFeedSource(ctx context.Context, req *pb.DeleteFeedSourceRequest) (*pb.DeleteFeedSourceResponse, error) {
+	id, err := primitive.ObjectIDFromHex(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err = h.feedSourceService.Delete(ctx, id); err != nil {
+		return nil, feedSourceErrorResponse(err)
+	}
+
+	return &pb.DeleteFeedSourceResponse{}, nil
+}
+
+func validateFeedSource(source core.FeedSource) error {
+	var messages filters.ValidationErrors
+
+	if strings.TrimSpace(source.Name) == "" {
+		messages = append(messages, filters.ErrorResponse{Field: "name", Message: "must be provided"})
+	}
+
+	if _, err := url.ParseRequestURI(source.URL); err != nil {
+		messages = append(messages, filters.ErrorResponse{Field: "url", Message: "must be a valid URL"})
+	}
+
+	if _, err := core.NextRun(source.Schedule, time.Now()); err != nil {
+		messages = append(messages, filters.ErrorResponse{Field: "schedule", Message: "invalid cron expression"})
+	}
+
+	messages = append(messages, fetchOptionsViolations(source.Options)...)
+
+	if len(messages) != 0 {
+		return messages
+	}
+
+	return nil
+}
+
+// sameOrigin reports whether the URLs a and b have the same scheme and host.
+func sameOrigin(a, b string) bool {
+	u, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+
+	v, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(u.Scheme, v.Scheme) && strings.EqualFold(u.Host, v.Host)
+}
+
+func feedSourceErrorResponse(err error) error {
+	if errors.Is(err, core.ErrFeedSourceNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+
+	return status.Error(codes.Unknown, err.Error())
+}
+
+func feedSourceFromPB(s *pb.FeedSource) core.FeedSource {
+	return core.FeedSource{
+		Name: s.GetName(),
+		URL:  s.GetUrl(),
+		Options: core.FetchOptions{
+			Format:   core.FeedFormat(strings.ToLower(s.GetFormat())),
+			CSV:      csvDialectFromPB(s.GetCsv()),
+			Auth:     feedAuthFromPB(s.GetAuth()),
+			Snapshot: s.GetSnapshot(),
+			Currency: strings.ToUpper(s.GetCurrency()),
+		},
+		Schedule: s.GetSchedule(),
+		Enabled:  s.GetEnabled(),
+	}
+}
+
+func feedSourceToPB(source core.FeedSource) *pb.FeedSource {
+	res := &pb.FeedSource{
+		Id:        source.ID.Hex(),
+		Name:      source.Name,
+		Url:       redactURL(source.URL),
+		Format:    string(source.Options.Format),
+		Csv:       csvDialectToPB(source.Options.CSV),
+		Auth:      feedAuthToPB(source.Options.Auth),
+		Snapshot:  source.Options.Snapshot,
+		Currency:  source.Options.Currency,
+		Schedule:  source.Schedule,
+		Enabled:   source.Enabled,
+		CreatedAt: timestamppb.New(source.CreatedAt),
+		UpdatedAt: timestamppb.New(source.UpdatedAt),
+	}
+
+	if !source.NextRunAt.IsZero() {
+		res.NextRunAt = timestamppb.New(source.NextRunAt)
+	}
+
+	if !source.LastRunAt.IsZero() {
+		res.LastRunAt = timestamppb.New(source.LastRunAt)
+	}
+
+	if !source.LastJobID.IsZero() {
+		res.LastJobId = source.LastJobID.Hex()
+	}
+
+	return res
+}
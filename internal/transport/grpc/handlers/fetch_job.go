@@ -0,0 +1,153 @@
+package grpcHandler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	pb "github.com/ernur-eskermes/product-store/pkg/domain"
+	"github.com/ernur-eskermes/product-store/pkg/filters"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type FetchJobService interface {
+	Create(ctx context.Context, url string, opts core.FetchOptions) (core.FetchJob, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (core.FetchJob, error)
+	GetAll(ctx context.Context, filter core.FetchJobFilter, f *filters.Filters) ([]core.FetchJob, error)
+	GetTotalRecords(ctx context.Context, filter core.FetchJobFilter) (int64, error)
+	Cancel(ctx context.Context, id primitive.ObjectID) (core.FetchJob, error)
+}
+
+func (h *ProductHandler) GetFetchJob(ctx context.Context, req *pb.GetFetchJobRequest) (*pb.FetchJob, error) {
+	id, err := primitive.ObjectIDFromHex(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	job, err := h.fetchJobService.GetByID(ctx, id)
+	if err != nil {
+		return nil, fetchJobErrorResponse(err)
+	}
+
+	return fetchJobToPB(job), nil
+}
+
+func (h *ProductHandler) ListFetchJobs(ctx context.Context, req *pb.ListFetchJobsRequest) (*pb.ListFetchJobsResponse, error) {
+	f := filters.New(
+		req.Page,
+		req.PageSize,
+		req.Sort,
+		core.FetchJobDefaultSort,
+		core.FetchJobSortSafeList,
+	)
+	filter := core.FetchJobFilter{Status: core.FetchJobStatus(req.GetStatus())}
+
+	if err := validateFetchJobFilter(f, filter); err != nil {
+		return nil, ErrorFilterResponse(err)
+	}
+
+	jobs, err := h.fetchJobService.GetAll(ctx, filter, f)
+	if err != nil {
+		return nil, status.Error(codes.Unknown, err.Error())
+	}
+
+	totalRecords, err := h.fetchJobService.GetTotalRecords(ctx, filter)
+	if err != nil {
+		return nil, status.Error(codes.Unknown, err.Error())
+	}
+
+	res := make([]*pb.FetchJob, 0, len(jobs))
+
+	for _, job := range jobs {
+		res = append(res, fetchJobToPB(job))
+	}
+
+	return &pb.ListFetchJobsResponse{
+		Results:  res,
+		Metadata: calculateMetadata(totalRecords, f.Page, f.PageSize),
+	}, nil
+}
+
+func (h *ProductHandler) CancelFetchJob(ctx context.Context, req *pb.CancelFetchJobRequest) (*pb.FetchJob, error) {
+	id, err := primitive.ObjectIDFromHex(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	job, err := h.fetchJobService.Cancel(ctx, id)
+	if err != nil {
+		return nil, fetchJobErrorResponse(err)
+	}
+
+	return fetchJobToPB(job), nil
+}
+
+func validateFetchJobFilter(f *filters.Filters, filter core.FetchJobFilter) error {
+	var messages filters.ValidationErrors
+
+	if err := filters.ValidateFilters(f); err != nil && !errors.As(err, &messages) {
+		return err
+	}
+
+	if filter.Status != "" && !validFetchJobStatus(filter.Status) {
+		messages = append(messages, filters.ErrorResponse{Field: "status", Message: "invalid status value"})
+	}
+
+	if len(messages) != 0 {
+		return messages
+	}
+
+	return nil
+}
+
+func validFetchJobStatus(s core.FetchJobStatus) bool {
+	for _, v := range core.FetchJobStatuses {
+		if s == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+func fetchJobErrorResponse(err error) error {
+	switch {
+	case errors.Is(err, core.ErrFetchJobNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, core.ErrFetchJobFinished):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Unknown, err.Error())
+	}
+}
+
+func fetchJobToPB(job core.FetchJob) *pb.FetchJob {
+	res := &pb.FetchJob{
+		Id:              job.ID.Hex(),
+		Url:             redactURL(job.URL),
+		Status:          string(job.Status),
+		RowsProcessed:   int64(job.RowsProcessed),
+		Errors:          job.Errors,
+		CancelRequested: job.CancelRequested,
+		CreatedAt:       timestamppb.New(job.CreatedAt),
+		Csv:             csvDialectToPB(job.Options.CSV),
+		Format:          string(job.Options.Format),
+		Report:          fetchReportToPB(job.Report),
+		DryRun:          job.Options.DryRun,
+		Snapshot:        job.Options.Snapshot,
+		Currency:        job.Options.Currency,
+	}
+
+	if !job.StartedAt.IsZero() {
+		res.StartedAt = timestamppb.New(job.StartedAt)
+	}
+
+	if !job.FinishedAt.IsZero() {
+		res.FinishedAt = timestamppb.New(job.FinishedAt)
+	}
+
+	return res
+}
@@ -0,0 +1,308 @@
+package grpcHandler_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	mock_grpcHandler "github.com/ernur-eskermes/product-store/internal/transport/grpc/mocks"
+	pb "github.com/ernur-eskermes/product-store/pkg/domain"
+	"github.com/ernur-eskermes/product-store/pkg/pagination"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func mockFetchJobClient(t *testing.T) (pb.ProductServiceClient, *mock_grpcHandler.MockFetchJobService) {
+	t.Helper()
+
+	mockCtl := gomock.NewController(t)
+	t.Cleanup(mockCtl.Finish)
+
+	productService := mock_grpcHandler.NewMockProductService(mockCtl)
+	fetchJobService := mock_grpcHandler.NewMockFetchJobService(mockCtl)
+	feedSourceService := mock_grpcHandler.NewMockFeedSourceService(mockCtl)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithContextDialer(dialer(productService, fetchJobService, feedSourceService)))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewProductServiceClient(conn), fetchJobService
+}
+
+func TestProductHandler_GetFetchJob(t *testing.T) {
+	type mockBehavior func(r *mock_grpcHandler.MockFetchJobService)
+
+	job := core.FetchJob{
+		ID:            primitive.NewObjectID(),
+		URL:           "https://some-url.com",
+		Options:       core.FetchOptions{Format: core.FeedFormatCSV, CSV: core.CSVDialect{Delimiter: ",", Columns: map[string]string{"title": "name", "cost": "price"}}},
+		Status:        core.FetchJobSucceeded,
+		RowsProcessed: 12,
+		Errors:        []string{},
+		Report: core.FetchReport{
+			RowsAccepted: 12,
+			RowsRejected: 1,
+			RejectedRows: []core.RowError{{Line: 4, Column: "cost", Reason: "must not be negative"}},
+		},
+		CreatedAt:  time.Date(2022, 7, 1, 10, 0, 0, 0, time.UTC),
+		StartedAt:  time.Date(2022, 7, 1, 10, 0, 1, 0, time.UTC),
+		FinishedAt: time.Date(2022, 7, 1, 10, 0, 5, 0, time.UTC),
+	}
+
+	cases := []struct {
+		name         string
+		id           string
+		expResp      core.FetchJob
+		errCode      codes.Code
+		errMsg       string
+		mockBehavior mockBehavior
+	}{
+		{
+			name:    "test_ok",
+			id:      job.ID.Hex(),
+			expResp: job,
+			errCode: codes.OK,
+			mockBehavior: func(r *mock_grpcHandler.MockFetchJobService) {
+				r.EXPECT().GetByID(gomock.Any(), job.ID).Return(job, nil)
+			},
+		},
+		{
+			name:         "invalid_id",
+			id:           "some-id",
+			errCode:      codes.InvalidArgument,
+			errMsg:       "the provided hex string is not a valid ObjectID",
+			mockBehavior: func(r *mock_grpcHandler.MockFetchJobService) {},
+		},
+		{
+			name:    "job_not_found",
+			id:      job.ID.Hex(),
+			errCode: codes.NotFound,
+			errMsg:  core.ErrFetchJobNotFound.Error(),
+			mockBehavior: func(r *mock_grpcHandler.MockFetchJobService) {
+				r.EXPECT().GetByID(gomock.Any(), job.ID).Return(core.FetchJob{}, core.ErrFetchJobNotFound)
+			},
+		},
+	}
+
+	client, fetchJobService := mockFetchJobClient(t)
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			s.mockBehavior(fetchJobService)
+
+			resp, err := client.GetFetchJob(context.Background(), &pb.GetFetchJobRequest{Id: s.id})
+			if s.errCode != codes.OK {
+				er, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, s.errCode, er.Code())
+				require.Equal(t, s.errMsg, er.Message())
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, s.expResp, PBFetchJobToStruct(resp))
+		})
+	}
+}
+
+func TestProductHandler_ListFetchJobs(t *testing.T) {
+	type mockBehavior func(r *mock_grpcHandler.MockFetchJobService)
+
+	jobs := []core.FetchJob{
+		{ID: primitive.NewObjectID(), URL: "https://some-url.com", Status: core.FetchJobPending, Errors: []string{}, CreatedAt: time.Date(2022, 7, 1, 10, 0, 0, 0, time.UTC)},
+		{ID: primitive.NewObjectID(), URL: "https://some-url.com", Status: core.FetchJobPending, Errors: []string{}, CreatedAt: time.Date(2022, 7, 1, 9, 0, 0, 0, time.UTC)},
+	}
+	metadata, _ := pagination.New(2, 1, 30)
+
+	cases := []struct {
+		name        string
+		req         *pb.ListFetchJobsRequest
+		expResp     []core.FetchJob
+		expMetadata *pagination.Pagination
+
+		expErr  map[string]string
+		errCode codes.Code
+		errMsg  string
+
+		mockBehavior mockBehavior
+	}{
+		{
+			name:        "test_ok",
+			req:         &pb.ListFetchJobsRequest{Status: "pending"},
+			expResp:     jobs,
+			expMetadata: metadata,
+			errCode:     codes.OK,
+			mockBehavior: func(r *mock_grpcHandler.MockFetchJobService) {
+				filter := core.FetchJobFilter{Status: core.FetchJobPending}
+				r.EXPECT().GetAll(gomock.Any(), filter, gomock.Any()).Return(jobs, nil)
+				r.EXPECT().GetTotalRecords(gomock.Any(), filter).Return(int64(2), nil)
+			},
+		},
+		{
+			name:         "invalid_filters",
+			req:          &pb.ListFetchJobsRequest{Status: "done", Sort: "name", PageSize: 300},
+			expErr:       map[string]string{"status": "invalid status value", "sort": "invalid sort value", "page_size": "must be a maximum of 100"},
+			errCode:      codes.InvalidArgument,
+			errMsg:       "invalid filter params",
+			mockBehavior: func(r *mock_grpcHandler.MockFetchJobService) {},
+		},
+		{
+			name:    "error_when_calling_GetAll_method",
+			req:     &pb.ListFetchJobsRequest{},
+			errCode: codes.Unknown,
+			errMsg:  "error",
+			mockBehavior: func(r *mock_grpcHandler.MockFetchJobService) {
+				r.EXPECT().GetAll(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("error"))
+			},
+		},
+	}
+
+	client, fetchJobService := mockFetchJobClient(t)
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			s.mockBehavior(fetchJobService)
+
+			resp, err := client.ListFetchJobs(context.Background(), s.req)
+			if s.errCode != codes.OK {
+				er, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, s.errCode, er.Code())
+				require.Equal(t, s.errMsg, er.Message())
+
+				for _, detail := range er.Details() {
+					if badReq, ok := detail.(*errdetails.BadRequest); ok {
+						require.EqualValues(t, s.expErr, badRequestToMap(badReq))
+					}
+				}
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			res := make([]core.FetchJob, 0, len(resp.Results))
+			for _, job := range resp.Results {
+				res = append(res, PBFetchJobToStruct(job))
+			}
+
+			require.Equal(t, s.expResp, res)
+			require.Equal(t, s.expMetadata, PBMetadataToStruct(resp.Metadata))
+		})
+	}
+}
+
+func TestProductHandler_CancelFetchJob(t *testing.T) {
+	type mockBehavior func(r *mock_grpcHandler.MockFetchJobService)
+
+	job := core.FetchJob{
+		ID:              primitive.NewObjectID(),
+		URL:             "https://some-url.com",
+		Status:          core.FetchJobRunning,
+		Errors:          []string{},
+		CancelRequested: true,
+		CreatedAt:       time.Date(2022, 7, 1, 10, 0, 0, 0, time.UTC),
+		StartedAt:       time.Date(2022, 7, 1, 10, 0, 1, 0, time.UTC),
+	}
+
+	cases := []struct {
+		name         string
+		expResp      core.FetchJob
+		errCode      codes.Code
+		errMsg       string
+		mockBehavior mockBehavior
+	}{
+		{
+			name:    "test_ok",
+			expResp: job,
+			errCode: codes.OK,
+			mockBehavior: func(r *mock_grpcHandler.MockFetchJobService) {
+				r.EXPECT().Cancel(gomock.Any(), job.ID).Return(job, nil)
+			},
+		},
+		{
+			name:    "job_already_finished",
+			errCode: codes.FailedPrecondition,
+			errMsg:  core.ErrFetchJobFinished.Error(),
+			mockBehavior: func(r *mock_grpcHandler.MockFetchJobService) {
+				r.EXPECT().Cancel(gomock.Any(), job.ID).Return(core.FetchJob{}, core.ErrFetchJobFinished)
+			},
+		},
+		{
+			name:    "error_when_calling_Cancel_method",
+			errCode: codes.Unknown,
+			errMsg:  "error",
+			mockBehavior: func(r *mock_grpcHandler.MockFetchJobService) {
+				r.EXPECT().Cancel(gomock.Any(), job.ID).Return(core.FetchJob{}, errors.New("error"))
+			},
+		},
+	}
+
+	client, fetchJobService := mockFetchJobClient(t)
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			s.mockBehavior(fetchJobService)
+
+			resp, err := client.CancelFetchJob(context.Background(), &pb.CancelFetchJobRequest{Id: job.ID.Hex()})
+			if s.errCode != codes.OK {
+				er, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, s.errCode, er.Code())
+				require.Equal(t, s.errMsg, er.Message())
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, s.expResp, PBFetchJobToStruct(resp))
+		})
+	}
+}
+
+func PBFetchJobToStruct(job *pb.FetchJob) core.FetchJob {
+	id, _ := primitive.ObjectIDFromHex(job.GetId())
+
+	res := core.FetchJob{
+		ID:              id,
+		URL:             job.GetUrl(),
+		Status:          core.FetchJobStatus(job.GetStatus()),
+		RowsProcessed:   int(job.GetRowsProcessed()),
+		Errors:          job.GetErrors(),
+		CancelRequested: job.GetCancelRequested(),
+		CreatedAt:       job.GetCreatedAt().AsTime(),
+		Report:          PBFetchReportToStruct(job.GetReport()),
+		Options: core.FetchOptions{
+			Format: core.FeedFormat(job.GetFormat()),
+			CSV: core.CSVDialect{
+				Delimiter: job.GetCsv().GetDelimiter(),
+				Quote:     job.GetCsv().GetQuote(),
+				NoHeader:  job.GetCsv().GetNoHeader(),
+				Columns:   job.GetCsv().GetColumns(),
+			},
+		},
+	}
+
+	if res.Errors == nil {
+		res.Errors = []string{}
+	}
+
+	if job.StartedAt != nil {
+		res.StartedAt = job.GetStartedAt().AsTime()
+	}
+
+	if job.FinishedAt != nil {
+		res.FinishedAt = job.GetFinishedAt().AsTime()
+	}
+
+	return res
+}
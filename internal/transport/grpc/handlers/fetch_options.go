@@ -0,0 +1,349 @@
+package grpcHandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	pb "github.com/ernur-eskermes/product-store/pkg/domain"
+	"github.com/ernur-eskermes/product-store/pkg/filters"
+	"github.com/ernur-eskermes/product-store/pkg/httpclient"
+	"github.com/ernur-eskermes/product-store/pkg/iso4217"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func fetchOptionsFromPB(req *pb.FetchRequest) core.FetchOptions {
+	return core.FetchOptions{
+		Format:   core.FeedFormat(strings.ToLower(req.GetFormat())),
+		CSV:      csvDialectFromPB(req.GetCsv()),
+		DryRun:   req.GetDryRun(),
+		Snapshot: req.GetSnapshot(),
+		Auth:     feedAuthFromPB(req.GetAuth()),
+		Currency: strings.ToUpper(req.GetCurrency()),
+	}
+}
+
+// moneyToPB leaves out the price of a product that has none, such as the old price of
+// a created one.
+func moneyToPB(m core.Money) *pb.Money {
+	if m == (core.Money{}) {
+		return nil
+	}
+
+	return &pb.Money{Amount: m.Amount, Currency: m.Currency}
+}
+
+func feedAuthFromPB(a *pb.FeedAuth) *core.FeedAuth {
+	if a == nil {
+		return nil
+	}
+
+	return &core.FeedAuth{
+		Secret:      a.GetSecret(),
+		Username:    a.GetUsername(),
+		Password:    a.GetPassword(),
+		BearerToken: a.GetBearerToken(),
+		HeaderName:  a.GetHeaderName(),
+		HeaderValue: a.GetHeaderValue(),
+	}
+}
+
+// feedAuthToPB leaves the secret values out, so they are never sent back. A bearer
+// token is sent as core.RedactedBearerToken.
+func feedAuthToPB(a *core.FeedAuth) *pb.FeedAuth {
+	if a == nil {
+		return nil
+	}
+
+	r := a.Redacted()
+
+	return &pb.FeedAuth{
+		Secret:      r.Secret,
+		Username:    r.Username,
+		BearerToken: r.BearerToken,
+		HeaderName:  r.HeaderName,
+	}
+}
+
+// redactURL hides the password of a URL carrying credentials.
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return s
+	}
+
+	return u.Redacted()
+}
+
+func csvDialectFromPB(d *pb.CSVDialect) core.CSVDialect {
+	return core.CSVDialect{
+		Delimiter: d.GetDelimiter(),
+		Quote:     d.GetQuote(),
+		NoHeader:  d.GetNoHeader(),
+		Columns:   d.GetColumns(),
+	}
+}
+
+func csvDialectToPB(d core.CSVDialect) *pb.CSVDialect {
+	return &pb.CSVDialect{
+		Delimiter: d.Delimiter,
+		Quote:     d.Quote,
+		NoHeader:  d.NoHeader,
+		Columns:   d.Columns,
+	}
+}
+
+func fetchReportToPB(r core.FetchReport) *pb.FetchReport {
+	res := &pb.FetchReport{
+		RowsRead:             int64(r.RowsRead),
+		RowsAccepted:         int64(r.RowsAccepted),
+		RowsRejected:         int64(r.RowsRejected),
+		RejectedRows:         make([]*pb.FetchReport_RowError, 0, len(r.RejectedRows)),
+		ProductsInserted:     int64(r.ProductsInserted),
+		ProductsRepriced:     int64(r.ProductsRepriced),
+		ProductsUnchanged:    int64(r.ProductsUnchanged),
+		BytesDownloaded:      r.BytesDownloaded,
+		Duration:             durationpb.New(r.Duration),
+		Skipped:              string(r.Skipped),
+		ProductsDiscontinued: int64(r.ProductsDiscontinued),
+		DiscontinueSkipped:   r.DiscontinueSkipped,
+		DuplicatesUnchecked:  r.DuplicatesUnchecked,
+	}
+
+	for _, e := range r.RejectedRows {
+		res.RejectedRows = append(res.RejectedRows, &pb.FetchReport_RowError{
+			File:   e.File,
+			Line:   int64(e.Line),
+			Column: e.Column,
+			Reason: e.Reason,
+		})
+	}
+
+	if r.Diff != nil {
+		res.Diff = &pb.CatalogDiff{
+			Created:   diffGroupToPB(r.Diff.Created),
+			Repriced:  diffGroupToPB(r.Diff.Repriced),
+			Unchanged: diffGroupToPB(r.Diff.Unchanged),
+			Missing:   diffGroupToPB(r.Diff.Missing),
+		}
+	}
+
+	return res
+}
+
+func diffGroupToPB(g core.DiffGroup) *pb.CatalogDiff_Group {
+	res := &pb.CatalogDiff_Group{
+		Count:   int64(g.Count),
+		Samples: make([]*pb.CatalogDiff_Product, 0, len(g.Samples)),
+	}
+
+	for _, p := range g.Samples {
+		res.Samples = append(res.Samples, &pb.CatalogDiff_Product{
+			Sku:      p.SKU,
+			Name:     p.Name,
+			OldPrice: moneyToPB(p.OldPrice),
+			NewPrice: moneyToPB(p.NewPrice),
+		})
+	}
+
+	return res
+}
+
+func validateFetchOptions(opts core.FetchOptions) error {
+	if messages := fetchOptionsViolations(opts); len(messages) != 0 {
+		return messages
+	}
+
+	return nil
+}
+
+func fetchOptionsViolations(opts core.FetchOptions) filters.ValidationErrors {
+	var messages filters.ValidationErrors
+
+	if opts.Format != "" && !validFeedFormat(opts.Format) {
+		messages = append(messages, filters.ErrorResponse{Field: "format", Message: "invalid format value"})
+	}
+
+	if opts.Currency != "" && !iso4217.Valid(opts.Currency) {
+		messages = append(messages, filters.ErrorResponse{Field: "currency", Message: core.ErrInvalidCurrency.Error()})
+	}
+
+	messages = append(messages, validateCSVDialect(opts.CSV)...)
+
+	if opts.Auth != nil {
+		if msg := validateFeedAuth(*opts.Auth); msg != "" {
+			messages = append(messages, filters.ErrorResponse{Field: "auth", Message: msg})
+		}
+	}
+
+	return messages
+}
+
+func validateFeedAuth(a core.FeedAuth) string {
+	kinds := 0
+
+	for _, set := range []bool{a.Secret != "", a.Username != "" || a.Password != "", a.BearerToken != "", a.HeaderName != "" || a.HeaderValue != ""} {
+		if set {
+			kinds++
+		}
+	}
+
+	switch {
+	case kinds > 1:
+		return "must hold one of a secret, basic auth, a bearer token or a header"
+	case a.Password != "" && a.Username == "":
+		return "username must be provided with a password"
+	case (a.HeaderName == "") != (a.HeaderValue == ""):
+		return "header_name and header_value must be provided together"
+	case a.HeaderName != "" && !validHeaderName(a.HeaderName):
+		return "header_name must be a valid header name"
+	default:
+		return ""
+	}
+}
+
+func validHeaderName(name string) bool {
+	for _, c := range name {
+		if c > unicode.MaxASCII || !(unicode.IsLetter(c) || unicode.IsDigit(c) || strings.ContainsRune("!#$%&'*+-.^_`|~", c)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func validateCSVDialect(d core.CSVDialect) filters.ValidationErrors {
+	var messages filters.ValidationErrors
+
+	delimiter, _ := utf8.DecodeRuneInString(d.Delimiter)
+	if d.Delimiter != "" {
+		if utf8.RuneCountInString(d.Delimiter) != 1 || delimiter == utf8.RuneError || delimiter == '\r' || delimiter == '\n' {
+			messages = append(messages, filters.ErrorResponse{Field: "csv.delimiter", Message: "must be a single character"})
+		}
+	}
+
+	// Feeds are quoted with '"' unless another quote is given.
+	quote := d.Quote
+	if quote == "" {
+		quote = `"`
+	}
+
+	if len(quote) != 1 || quote[0] >= utf8.RuneSelf || quote == "\r" || quote == "\n" {
+		messages = append(messages, filters.ErrorResponse{Field: "csv.quote", Message: "must be a single ASCII character"})
+	} else if quote == d.Delimiter {
+		messages = append(messages, filters.ErrorResponse{Field: "csv.quote", Message: "must differ from the delimiter"})
+	}
+
+	if len(d.Columns) != 0 {
+		if msg := validateCSVColumns(d); msg != "" {
+			messages = append(messages, filters.ErrorResponse{Field: "csv.columns", Message: msg})
+		}
+	}
+
+	return messages
+}
+
+func validateCSVColumns(d core.CSVDialect) string {
+	mapped := make(map[string]bool, len(d.Columns))
+
+	for column, field := range d.Columns {
+		if !validProductField(field) {
+			return fmt.Sprintf("unknown product field %q", field)
+		}
+
+		if mapped[field] {
+			return fmt.Sprintf("product field %q is mapped more than once", field)
+		}
+
+		mapped[field] = true
+
+		if d.NoHeader {
+			if i, err := strconv.Atoi(column); err != nil || i < 1 {
+				return fmt.Sprintf("column %q must be a positive position when no_header is set", column)
+			}
+		}
+	}
+
+	for _, field := range []string{core.ProductFieldName, core.ProductFieldPrice} {
+		if !mapped[field] {
+			return fmt.Sprintf("product field %q must be mapped", field)
+		}
+	}
+
+	return ""
+}
+
+func validProductField(field string) bool {
+	for _, v := range core.ProductFields {
+		if field == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validFeedFormat(format core.FeedFormat) bool {
+	for _, v := range core.FeedFormats {
+		if format == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fetchErrorResponse maps a failed fetch to a gRPC status. A feed server that cannot be
+// reached, or is failing, makes the feed Unavailable, a missing feed is NotFound and
+// any other refusal is FailedPrecondition, since the request is fine but the feed
+// cannot be fetched as it is set up. Downloads refused by the download policy, and
+// files outside the feed root, are PermissionDenied. URLs of an unsupported scheme and
+// feeds that cannot be parsed are InvalidArgument.
+func fetchErrorResponse(err error) error {
+	var (
+		policyErr   *httpclient.PolicyError
+		statusErr   *core.HTTPStatusError
+		downloadErr *core.DownloadError
+		feedErr     *core.FeedError
+	)
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.As(err, &policyErr), errors.Is(err, core.ErrFeedOutsideRoot):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, core.ErrUnsupportedScheme):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, fs.ErrNotExist):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, core.ErrUnknownSecret):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.As(err, &statusErr):
+		switch {
+		case statusErr.Temporary():
+			return status.Error(codes.Unavailable, err.Error())
+		case statusErr.StatusCode == http.StatusNotFound, statusErr.StatusCode == http.StatusGone:
+			return status.Error(codes.NotFound, err.Error())
+		default:
+			return status.Error(codes.FailedPrecondition, err.Error())
+		}
+	case errors.As(err, &downloadErr):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.As(err, &feedErr):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Unknown, err.Error())
+	}
+}
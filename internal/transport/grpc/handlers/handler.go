@@ -5,11 +5,13 @@ type Handler struct {
 }
 
 type Deps struct {
-	ProductService ProductService
+	ProductService    ProductService
+	FetchJobService   FetchJobService
+	FeedSourceService FeedSourceService
 }
 
 func New(deps Deps) *Handler {
 	return &Handler{
-		Product: NewProductHandler(deps.ProductService),
+		Product: NewProductHandler(deps.ProductService, deps.FetchJobService, deps.FeedSourceService),
 	}
 }
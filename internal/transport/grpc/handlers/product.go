@@ -5,48 +5,69 @@ import (
 	"errors"
 	"io"
 	"net/url"
+	"strings"
 
 	"github.com/ernur-eskermes/product-store/pkg/pagination"
 
 	"github.com/ernur-eskermes/product-store/internal/core"
 	pb "github.com/ernur-eskermes/product-store/pkg/domain"
 	"github.com/ernur-eskermes/product-store/pkg/filters"
-	"github.com/golang/protobuf/ptypes/empty"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type ProductService interface {
-	UpdateOrCreate(ctx context.Context, products []core.Product) error
-	GetAll(ctx context.Context, f *filters.Filters) ([]core.Product, error)
-	GetTotalRecords(ctx context.Context) (int64, error)
-	GetCSVProducts(ctx context.Context, url string) ([]core.Product, error)
+	Fetch(ctx context.Context, url string, opts core.FetchOptions, progress func(rows int)) (core.FetchReport, error)
+	Upload(ctx context.Context, name string, r io.Reader, opts core.FetchOptions) (core.FetchReport, error)
+	GetAll(ctx context.Context, filter core.ProductFilter, f *filters.Filters, pageToken string) ([]core.Product, string, error)
+	GetTotalRecords(ctx context.Context, filter core.ProductFilter) (int64, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (core.Product, error)
+	GetBySKU(ctx context.Context, sku string) (core.Product, error)
+	Search(ctx context.Context, query string, filter core.ProductFilter, f *filters.Filters) ([]core.SearchResult, error)
+	GetSearchTotalRecords(ctx context.Context, query string, filter core.ProductFilter) (int64, error)
+	Suggest(ctx context.Context, prefix string, limit int) ([]string, error)
+	GetPriceHistory(ctx context.Context, filter core.PriceHistoryFilter, f *filters.Filters) ([]core.PriceChange, error)
+	GetPriceHistoryTotalRecords(ctx context.Context, filter core.PriceHistoryFilter) (int64, error)
 }
 
 type ProductHandler struct {
-	service ProductService
+	service           ProductService
+	fetchJobService   FetchJobService
+	feedSourceService FeedSourceService
 	pb.UnimplementedProductServiceServer
 }
 
-func NewProductHandler(s ProductService) *ProductHandler {
-	return &ProductHandler{service: s}
+func NewProductHandler(s ProductService, fetchJobService FetchJobService, feedSourceService FeedSourceService) *ProductHandler {
+	return &ProductHandler{service: s, fetchJobService: fetchJobService, feedSourceService: feedSourceService}
 }
 
-func (h *ProductHandler) Fetch(ctx context.Context, req *pb.FetchRequest) (*empty.Empty, error) {
+func (h *ProductHandler) Fetch(ctx context.Context, req *pb.FetchRequest) (*pb.FetchResponse, error) {
 	if _, err := url.ParseRequestURI(req.GetUrl()); err != nil {
-		return &empty.Empty{}, status.Error(codes.InvalidArgument, err.Error())
+		return &pb.FetchResponse{}, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	products, err := h.service.GetCSVProducts(ctx, req.GetUrl())
-	if err != nil {
-		return &empty.Empty{}, status.Error(codes.InvalidArgument, err.Error())
+	opts := fetchOptionsFromPB(req)
+	if err := validateFetchOptions(opts); err != nil {
+		return &pb.FetchResponse{}, ErrorFilterResponse(err)
 	}
 
-	if err = h.service.UpdateOrCreate(ctx, products); err != nil {
-		return &empty.Empty{}, status.Error(codes.Unknown, err.Error())
+	if req.GetAsync() {
+		job, err := h.fetchJobService.Create(ctx, req.GetUrl(), opts)
+		if err != nil {
+			return &pb.FetchResponse{}, status.Error(codes.Unknown, err.Error())
+		}
+
+		return &pb.FetchResponse{JobId: job.ID.Hex()}, nil
 	}
 
-	return &empty.Empty{}, nil
+	report, err := h.service.Fetch(ctx, req.GetUrl(), opts, nil)
+	if err != nil {
+		return &pb.FetchResponse{}, fetchErrorResponse(err)
+	}
+
+	return &pb.FetchResponse{Report: fetchReportToPB(report)}, nil
 }
 
 func (h *ProductHandler) List(stream pb.ProductService_ListServer) error {
@@ -67,35 +88,232 @@ func (h *ProductHandler) List(stream pb.ProductService_ListServer) error {
 			core.ProductDefaultSort,
 			core.ProductSortSafeList,
 		)
-		if err = filters.ValidateFilters(f); err != nil {
+		f.MinPrice = priceFilterFromPB(req.GetMinPrice())
+		f.MaxPrice = priceFilterFromPB(req.GetMaxPrice())
+		f.NamePrefix = req.GetNamePrefix()
+		f.NameContains = req.GetNameContains()
+
+		if err = validateListFilters(f, req); err != nil {
 			return ErrorFilterResponse(err)
 		}
 
-		products, err := h.service.GetAll(context.TODO(), f)
+		filter := productFilter(f, req)
+
+		products, nextPageToken, err := h.service.GetAll(context.TODO(), filter, f, req.GetPageToken())
+		if errors.Is(err, core.ErrInvalidPageToken) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+
 		if err != nil {
 			return status.Error(codes.Unknown, err.Error())
 		}
 
-		totalRecords, err := h.service.GetTotalRecords(context.TODO())
+		totalRecords, err := h.service.GetTotalRecords(context.TODO(), filter)
 		if err != nil {
 			return status.Error(codes.Unknown, err.Error())
 		}
 
-		res := make([]*pb.ListResponse_Product, 0, len(products))
+		res := make([]*pb.Product, 0, len(products))
 
 		for _, product := range products {
-			res = append(res, &pb.ListResponse_Product{Name: product.Name, Price: int64(product.Price)})
+			res = append(res, productToPB(product))
+		}
+
+		// There is no metadata, and no next page, when no product matches. A page read by
+		// token has no number.
+		metadata := calculateMetadata(totalRecords, f.Page, f.PageSize)
+		if metadata != nil {
+			metadata.NextPageToken = nextPageToken
+
+			if req.GetPageToken() != "" {
+				metadata.CurrentPage, metadata.FirstPage, metadata.LastPage = 0, 0, 0
+			}
 		}
 
 		if err = stream.Send(&pb.ListResponse{
 			Results:  res,
-			Metadata: calculateMetadata(totalRecords, f.Page, f.PageSize),
+			Metadata: metadata,
 		}); err != nil {
 			return err
 		}
 	}
 }
 
+func priceFilterFromPB(m *pb.Money) *filters.Price {
+	if m == nil {
+		return nil
+	}
+
+	return &filters.Price{Amount: m.GetAmount(), Currency: strings.ToUpper(m.GetCurrency())}
+}
+
+// productFilter returns the filter of the products listed for req, whose filters f
+// hold.
+func productFilter(f *filters.Filters, req *pb.Filters) core.ProductFilter {
+	filter := core.ProductFilter{
+		IncludeDiscontinued: req.GetIncludeDiscontinued(),
+		NamePrefix:          f.NamePrefix,
+		NameContains:        f.NameContains,
+	}
+
+	if f.MinPrice != nil {
+		filter.MinPrice = &core.Money{Amount: f.MinPrice.Amount, Currency: f.MinPrice.Currency}
+	}
+
+	if f.MaxPrice != nil {
+		filter.MaxPrice = &core.Money{Amount: f.MaxPrice.Amount, Currency: f.MaxPrice.Currency}
+	}
+
+	return filter
+}
+
+// validateListFilters validates f, and that a page is not asked for both by number and
+// by token.
+func validateListFilters(f *filters.Filters, req *pb.Filters) error {
+	var messages filters.ValidationErrors
+
+	if err := filters.ValidateFilters(f); err != nil {
+		errors.As(err, &messages)
+	}
+
+	if req.GetPageToken() != "" && req.GetPage() != 0 {
+		messages = append(messages, filters.ErrorResponse{Field: "page_token", Message: "must not be set with page"})
+	}
+
+	if len(messages) != 0 {
+		return messages
+	}
+
+	return nil
+}
+
+func (h *ProductHandler) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.Product, error) {
+	var (
+		product core.Product
+		err     error
+	)
+
+	switch key := req.GetKey().(type) {
+	case *pb.GetProductRequest_Id:
+		id, idErr := primitive.ObjectIDFromHex(key.Id)
+		if idErr != nil {
+			return nil, status.Error(codes.InvalidArgument, idErr.Error())
+		}
+
+		product, err = h.service.GetByID(ctx, id)
+	case *pb.GetProductRequest_Sku:
+		if key.Sku == "" {
+			return nil, status.Error(codes.InvalidArgument, "sku must not be empty")
+		}
+
+		product, err = h.service.GetBySKU(ctx, key.Sku)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "id or sku must be provided")
+	}
+
+	if errors.Is(err, core.ErrProductNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	if err != nil {
+		return nil, status.Error(codes.Unknown, err.Error())
+	}
+
+	return productToPB(product), nil
+}
+
+func productToPB(product core.Product) *pb.Product {
+	res := &pb.Product{
+		Sku:              product.SKU,
+		Name:             product.Name,
+		Price:            moneyToPB(product.Price),
+		PriceChangeCount: int64(product.PriceChangeCount),
+		UpdatedAt:        timestamppb.New(product.UpdatedAt),
+		SourceUrl:        redactURL(product.SourceURL),
+	}
+
+	if !product.ID.IsZero() {
+		res.Id = product.ID.Hex()
+	}
+
+	if product.Discontinued() {
+		res.DiscontinuedAt = timestamppb.New(product.DiscontinuedAt)
+	}
+
+	return res
+}
+
+func (h *ProductHandler) GetPriceHistory(ctx context.Context, req *pb.GetPriceHistoryRequest) (*pb.GetPriceHistoryResponse, error) {
+	f := filters.New(
+		req.Page,
+		req.PageSize,
+		req.Sort,
+		core.PriceHistoryDefaultSort,
+		core.PriceHistorySortSafeList,
+	)
+
+	filter := core.PriceHistoryFilter{ProductName: req.GetName(), SKU: strings.TrimSpace(req.GetSku())}
+	if req.From != nil {
+		filter.From = req.GetFrom().AsTime()
+	}
+
+	if req.To != nil {
+		filter.To = req.GetTo().AsTime()
+	}
+
+	if err := validatePriceHistoryRequest(f, filter); err != nil {
+		return nil, ErrorFilterResponse(err)
+	}
+
+	changes, err := h.service.GetPriceHistory(ctx, filter, f)
+	if err != nil {
+		return nil, status.Error(codes.Unknown, err.Error())
+	}
+
+	totalRecords, err := h.service.GetPriceHistoryTotalRecords(ctx, filter)
+	if err != nil {
+		return nil, status.Error(codes.Unknown, err.Error())
+	}
+
+	res := make([]*pb.GetPriceHistoryResponse_PriceChange, 0, len(changes))
+
+	for _, change := range changes {
+		res = append(res, &pb.GetPriceHistoryResponse_PriceChange{
+			OldPrice:  moneyToPB(change.OldPrice),
+			NewPrice:  moneyToPB(change.NewPrice),
+			FetchedAt: timestamppb.New(change.FetchedAt),
+			SourceUrl: redactURL(change.SourceURL),
+		})
+	}
+
+	return &pb.GetPriceHistoryResponse{
+		Results:  res,
+		Metadata: calculateMetadata(totalRecords, f.Page, f.PageSize),
+	}, nil
+}
+
+func validatePriceHistoryRequest(f *filters.Filters, filter core.PriceHistoryFilter) error {
+	var messages filters.ValidationErrors
+
+	if err := filters.ValidateFilters(f); err != nil && !errors.As(err, &messages) {
+		return err
+	}
+
+	if filter.ProductName == "" && filter.SKU == "" {
+		messages = append(messages, filters.ErrorResponse{Field: "name", Message: "must be provided"})
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		messages = append(messages, filters.ErrorResponse{Field: "to", Message: "must not be earlier than from"})
+	}
+
+	if len(messages) != 0 {
+		return messages
+	}
+
+	return nil
+}
+
 func calculateMetadata(totalRecords, page, pageSize int64) *pb.ListResponse_MetaData {
 	p, err := pagination.New(totalRecords, page, pageSize)
 	if err != nil {
@@ -0,0 +1,126 @@
+package grpcHandler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	pb "github.com/ernur-eskermes/product-store/pkg/domain"
+	"github.com/ernur-eskermes/product-store/pkg/filters"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *ProductHandler) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	// Results are ranked by relevance, so there is no sort to pick.
+	f := filters.New(req.GetPage(), req.GetPageSize(), "", "", nil)
+
+	if err := validateSearchRequest(f, req.GetQuery()); err != nil {
+		return nil, ErrorFilterResponse(err)
+	}
+
+	filter := core.ProductFilter{IncludeDiscontinued: req.GetIncludeDiscontinued()}
+
+	results, err := h.service.Search(ctx, req.GetQuery(), filter, f)
+	if err != nil {
+		return nil, status.Error(codes.Unknown, err.Error())
+	}
+
+	totalRecords, err := h.service.GetSearchTotalRecords(ctx, req.GetQuery(), filter)
+	if err != nil {
+		return nil, status.Error(codes.Unknown, err.Error())
+	}
+
+	res := make([]*pb.SearchResponse_Result, 0, len(results))
+
+	for _, r := range results {
+		highlights := make([]*pb.SearchResponse_Highlight, 0, len(r.Highlights))
+		for _, hl := range r.Highlights {
+			highlights = append(highlights, &pb.SearchResponse_Highlight{
+				Field: hl.Field,
+				Start: int64(hl.Start),
+				End:   int64(hl.End),
+			})
+		}
+
+		res = append(res, &pb.SearchResponse_Result{
+			Product:    productToPB(r.Product),
+			Score:      r.Score,
+			Highlights: highlights,
+		})
+	}
+
+	return &pb.SearchResponse{
+		Results:  res,
+		Metadata: calculateMetadata(totalRecords, f.Page, f.PageSize),
+	}, nil
+}
+
+func validateSearchRequest(f *filters.Filters, query string) error {
+	var messages filters.ValidationErrors
+
+	if err := filters.ValidateFilters(f); err != nil && !errors.As(err, &messages) {
+		return err
+	}
+
+	switch {
+	case strings.TrimSpace(query) == "":
+		messages = append(messages, filters.ErrorResponse{Field: "query", Message: "must be provided"})
+	case !utf8.ValidString(query):
+		messages = append(messages, filters.ErrorResponse{Field: "query", Message: "must be valid UTF-8"})
+	case utf8.RuneCountInString(query) > core.MaxSearchQueryLength:
+		messages = append(messages, filters.ErrorResponse{Field: "query", Message: "must be a maximum of 255 characters"})
+	}
+
+	if len(messages) != 0 {
+		return messages
+	}
+
+	return nil
+}
+
+func (h *ProductHandler) Suggest(ctx context.Context, req *pb.SuggestRequest) (*pb.SuggestResponse, error) {
+	if err := validateSuggestRequest(req); err != nil {
+		return nil, ErrorFilterResponse(err)
+	}
+
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = core.DefaultSuggestLimit
+	}
+
+	names, err := h.service.Suggest(ctx, req.GetPrefix(), limit)
+	if err != nil {
+		return nil, status.Error(codes.Unknown, err.Error())
+	}
+
+	return &pb.SuggestResponse{Names: names}, nil
+}
+
+func validateSuggestRequest(req *pb.SuggestRequest) error {
+	var messages filters.ValidationErrors
+
+	switch prefix := req.GetPrefix(); {
+	case strings.TrimSpace(prefix) == "":
+		messages = append(messages, filters.ErrorResponse{Field: "prefix", Message: "must be provided"})
+	case !utf8.ValidString(prefix):
+		messages = append(messages, filters.ErrorResponse{Field: "prefix", Message: "must be valid UTF-8"})
+	case utf8.RuneCountInString(prefix) > core.MaxSearchQueryLength:
+		messages = append(messages, filters.ErrorResponse{Field: "prefix", Message: "must be a maximum of 255 characters"})
+	}
+
+	switch limit := req.GetLimit(); {
+	case limit < 0:
+		messages = append(messages, filters.ErrorResponse{Field: "limit", Message: "must be greater than zero"})
+	case limit > core.MaxSuggestLimit:
+		messages = append(messages, filters.ErrorResponse{Field: "limit", Message: "must be a maximum of 50"})
+	}
+
+	if len(messages) != 0 {
+		return messages
+	}
+
+	return nil
+}
@@ -0,0 +1,230 @@
+package grpcHandler_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	mock_grpcHandler "github.com/ernur-eskermes/product-store/internal/transport/grpc/mocks"
+	pb "github.com/ernur-eskermes/product-store/pkg/domain"
+	"github.com/ernur-eskermes/product-store/pkg/filters"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestProductHandler_Search(t *testing.T) {
+	type mockBehavior func(r *mock_grpcHandler.MockProductService)
+
+	product := core.Product{ID: primitive.NewObjectID(), Name: "Green apple", Price: usd(12), UpdatedAt: time.Date(2022, 7, 1, 10, 0, 0, 0, time.UTC)}
+	results := []core.SearchResult{{
+		Product:    product,
+		Score:      1.5,
+		Highlights: []core.Highlight{{Field: "name", Start: 6, End: 11}},
+	}}
+
+	cases := []struct {
+		name        string
+		req         *pb.SearchRequest
+		expResp     []*pb.SearchResponse_Result
+		expMetadata *pb.ListResponse_MetaData
+
+		expErr  map[string]string
+		errCode codes.Code
+		errMsg  string
+
+		mockBehavior mockBehavior
+	}{
+		{
+			name: "test_ok",
+			req:  &pb.SearchRequest{Query: "apple", PageSize: 10, IncludeDiscontinued: true},
+			expResp: []*pb.SearchResponse_Result{{
+				Score:      1.5,
+				Highlights: []*pb.SearchResponse_Highlight{{Field: "name", Start: 6, End: 11}},
+			}},
+			expMetadata: &pb.ListResponse_MetaData{CurrentPage: 1, PageSize: 10, FirstPage: 1, LastPage: 1, TotalRecords: 1},
+			errCode:     codes.OK,
+
+			mockBehavior: func(r *mock_grpcHandler.MockProductService) {
+				filter := core.ProductFilter{IncludeDiscontinued: true}
+				r.EXPECT().Search(gomock.Any(), "apple", filter, &filters.Filters{Page: 1, PageSize: 10}).Return(results, nil)
+				r.EXPECT().GetSearchTotalRecords(gomock.Any(), "apple", filter).Return(int64(1), nil)
+			},
+		},
+		{
+			name:         "empty_query",
+			req:          &pb.SearchRequest{Query: "  ", PageSize: 300},
+			expErr:       map[string]string{"query": "must be provided", "page_size": "must be a maximum of 100"},
+			errCode:      codes.InvalidArgument,
+			errMsg:       "invalid filter params",
+			mockBehavior: func(r *mock_grpcHandler.MockProductService) {},
+		},
+		{
+			name:         "long_query",
+			req:          &pb.SearchRequest{Query: strings.Repeat("a", 256)},
+			expErr:       map[string]string{"query": "must be a maximum of 255 characters"},
+			errCode:      codes.InvalidArgument,
+			errMsg:       "invalid filter params",
+			mockBehavior: func(r *mock_grpcHandler.MockProductService) {},
+		},
+		{
+			name:    "error_when_calling_Search_method",
+			req:     &pb.SearchRequest{Query: "apple"},
+			errCode: codes.Unknown,
+			errMsg:  "error when search",
+
+			mockBehavior: func(r *mock_grpcHandler.MockProductService) {
+				r.EXPECT().Search(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("error when search"))
+			},
+		},
+	}
+
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	ctx := context.Background()
+
+	productService := mock_grpcHandler.NewMockProductService(mockCtl)
+	fetchJobService := mock_grpcHandler.NewMockFetchJobService(mockCtl)
+	feedSourceService := mock_grpcHandler.NewMockFeedSourceService(mockCtl)
+
+	conn, err := grpc.DialContext(ctx, "bufnet", grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithContextDialer(dialer(productService, fetchJobService, feedSourceService)))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := pb.NewProductServiceClient(conn)
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			s.mockBehavior(productService)
+
+			resp, err := client.Search(ctx, s.req)
+			if s.errCode != codes.OK {
+				requireBadRequest(t, err, s.errCode, s.errMsg, s.expErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, resp.GetResults(), len(s.expResp))
+
+			for i, r := range resp.GetResults() {
+				require.Equal(t, []core.Product{product}, PBProductToStruct([]*pb.Product{r.GetProduct()}))
+				require.Equal(t, s.expResp[i].GetScore(), r.GetScore())
+				require.Len(t, r.GetHighlights(), len(s.expResp[i].GetHighlights()))
+
+				for j, hl := range r.GetHighlights() {
+					exp := s.expResp[i].GetHighlights()[j]
+					require.Equal(t, []interface{}{exp.GetField(), exp.GetStart(), exp.GetEnd()}, []interface{}{hl.GetField(), hl.GetStart(), hl.GetEnd()})
+				}
+			}
+
+			require.Equal(t, s.expMetadata.GetTotalRecords(), resp.GetMetadata().GetTotalRecords())
+			require.Equal(t, s.expMetadata.GetLastPage(), resp.GetMetadata().GetLastPage())
+		})
+	}
+}
+
+func TestProductHandler_Suggest(t *testing.T) {
+	type mockBehavior func(r *mock_grpcHandler.MockProductService)
+
+	cases := []struct {
+		name     string
+		req      *pb.SuggestRequest
+		expNames []string
+
+		expErr  map[string]string
+		errCode codes.Code
+		errMsg  string
+
+		mockBehavior mockBehavior
+	}{
+		{
+			name:     "test_ok",
+			req:      &pb.SuggestRequest{Prefix: "gre", Limit: 2},
+			expNames: []string{"Green Apple", "Green Pear"},
+			errCode:  codes.OK,
+
+			mockBehavior: func(r *mock_grpcHandler.MockProductService) {
+				r.EXPECT().Suggest(gomock.Any(), "gre", 2).Return([]string{"Green Apple", "Green Pear"}, nil)
+			},
+		},
+		{
+			name:     "default_limit",
+			req:      &pb.SuggestRequest{Prefix: "gre"},
+			expNames: []string{},
+			errCode:  codes.OK,
+
+			mockBehavior: func(r *mock_grpcHandler.MockProductService) {
+				r.EXPECT().Suggest(gomock.Any(), "gre", core.DefaultSuggestLimit).Return(nil, nil)
+			},
+		},
+		{
+			name:         "empty_prefix",
+			req:          &pb.SuggestRequest{Prefix: " ", Limit: 51},
+			expErr:       map[string]string{"prefix": "must be provided", "limit": "must be a maximum of 50"},
+			errCode:      codes.InvalidArgument,
+			errMsg:       "invalid filter params",
+			mockBehavior: func(r *mock_grpcHandler.MockProductService) {},
+		},
+		{
+			name:         "invalid_prefix",
+			req:          &pb.SuggestRequest{Prefix: strings.Repeat("a", 256), Limit: -1},
+			expErr:       map[string]string{"prefix": "must be a maximum of 255 characters", "limit": "must be greater than zero"},
+			errCode:      codes.InvalidArgument,
+			errMsg:       "invalid filter params",
+			mockBehavior: func(r *mock_grpcHandler.MockProductService) {},
+		},
+		{
+			name:    "error_when_calling_Suggest_method",
+			req:     &pb.SuggestRequest{Prefix: "gre"},
+			errCode: codes.Unknown,
+			errMsg:  "error when suggest",
+
+			mockBehavior: func(r *mock_grpcHandler.MockProductService) {
+				r.EXPECT().Suggest(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("error when suggest"))
+			},
+		},
+	}
+
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	ctx := context.Background()
+
+	productService := mock_grpcHandler.NewMockProductService(mockCtl)
+	fetchJobService := mock_grpcHandler.NewMockFetchJobService(mockCtl)
+	feedSourceService := mock_grpcHandler.NewMockFeedSourceService(mockCtl)
+
+	conn, err := grpc.DialContext(ctx, "bufnet", grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithContextDialer(dialer(productService, fetchJobService, feedSourceService)))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := pb.NewProductServiceClient(conn)
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			s.mockBehavior(productService)
+
+			resp, err := client.Suggest(ctx, s.req)
+			if s.errCode != codes.OK {
+				requireBadRequest(t, err, s.errCode, s.errMsg, s.expErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, resp.GetNames(), len(s.expNames))
+
+			for i, name := range resp.GetNames() {
+				require.Equal(t, s.expNames[i], name)
+			}
+		})
+	}
+}
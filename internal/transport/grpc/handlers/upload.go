@@ -0,0 +1,93 @@
+package grpcHandler
+
+import (
+	"errors"
+	"io"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	pb "github.com/ernur-eskermes/product-store/pkg/domain"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errUploadMetadata = status.Error(codes.InvalidArgument, "the first message must carry the upload metadata, and only the first")
+
+// Upload ingests a feed sent by the client: the metadata in the first message, then the
+// feed bytes. The bytes are handed to the service as they arrive.
+func (h *ProductHandler) Upload(stream pb.ProductService_UploadServer) error {
+	first, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return errUploadMetadata
+	}
+
+	if err != nil {
+		return err
+	}
+
+	meta := first.GetMetadata()
+	if meta == nil {
+		return errUploadMetadata
+	}
+
+	opts := uploadOptionsFromPB(meta)
+	if err = validateFetchOptions(opts); err != nil {
+		return ErrorFilterResponse(err)
+	}
+
+	r := &uploadReader{stream: stream}
+
+	report, err := h.service.Upload(stream.Context(), meta.GetName(), r, opts)
+	if r.err != nil {
+		return r.err
+	}
+
+	if err != nil {
+		return fetchErrorResponse(err)
+	}
+
+	return stream.SendAndClose(&pb.FetchResponse{Report: fetchReportToPB(report)})
+}
+
+func uploadOptionsFromPB(meta *pb.UploadMetadata) core.FetchOptions {
+	return fetchOptionsFromPB(&pb.FetchRequest{
+		Format:   meta.GetFormat(),
+		Csv:      meta.GetCsv(),
+		DryRun:   meta.GetDryRun(),
+		Snapshot: meta.GetSnapshot(),
+		Currency: meta.GetCurrency(),
+	})
+}
+
+// uploadReader reads the feed bytes of an upload. err keeps the error that broke the
+// stream off, so that it is not mistaken for a malformed feed.
+type uploadReader struct {
+	stream pb.ProductService_UploadServer
+	buf    []byte
+	err    error
+}
+
+func (r *uploadReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		chunk, err := r.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return 0, io.EOF
+		}
+
+		if err == nil && chunk.GetMetadata() != nil {
+			err = errUploadMetadata
+		}
+
+		if err != nil {
+			r.err = err
+
+			return 0, err
+		}
+
+		r.buf = chunk.GetData()
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+
+	return n, nil
+}
@@ -0,0 +1,155 @@
+package grpcHandler_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/ernur-eskermes/product-store/internal/core"
+	mock_grpcHandler "github.com/ernur-eskermes/product-store/internal/transport/grpc/mocks"
+	pb "github.com/ernur-eskermes/product-store/pkg/domain"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func mockUploadClient(t *testing.T) (pb.ProductServiceClient, *mock_grpcHandler.MockProductService) {
+	t.Helper()
+
+	mockCtl := gomock.NewController(t)
+	t.Cleanup(mockCtl.Finish)
+
+	productService := mock_grpcHandler.NewMockProductService(mockCtl)
+	fetchJobService := mock_grpcHandler.NewMockFetchJobService(mockCtl)
+	feedSourceService := mock_grpcHandler.NewMockFeedSourceService(mockCtl)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithContextDialer(dialer(productService, fetchJobService, feedSourceService)))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewProductServiceClient(conn), productService
+}
+
+func metadataChunk(meta *pb.UploadMetadata) *pb.UploadChunk {
+	return &pb.UploadChunk{Payload: &pb.UploadChunk_Metadata{Metadata: meta}}
+}
+
+func dataChunk(data string) *pb.UploadChunk {
+	return &pb.UploadChunk{Payload: &pb.UploadChunk_Data{Data: []byte(data)}}
+}
+
+func TestProductHandler_Upload(t *testing.T) {
+	type mockBehavior func(r *mock_grpcHandler.MockProductService)
+
+	report := core.FetchReport{RowsRead: 2, RowsAccepted: 2, ProductsInserted: 2, BytesDownloaded: 50}
+
+	cases := []struct {
+		name    string
+		chunks  []*pb.UploadChunk
+		expResp core.FetchReport
+		errCode codes.Code
+		errMsg  string
+
+		mockBehavior mockBehavior
+	}{
+		{
+			name: "test_ok",
+			chunks: []*pb.UploadChunk{
+				metadataChunk(&pb.UploadMetadata{Name: "products.csv", Format: "CSV", Csv: &pb.CSVDialect{Delimiter: ","}}),
+				dataChunk("PRODUCT NAME,PRICE\nTest Product,1000\n"),
+				dataChunk("Test Product2,2538\n"),
+			},
+			expResp: report,
+			errCode: codes.OK,
+			mockBehavior: func(r *mock_grpcHandler.MockProductService) {
+				opts := core.FetchOptions{Format: core.FeedFormatCSV, CSV: core.CSVDialect{Delimiter: ","}}
+
+				r.EXPECT().Upload(gomock.Any(), "products.csv", gomock.Any(), opts).DoAndReturn(func(_ context.Context, _ string, body io.Reader, _ core.FetchOptions) (core.FetchReport, error) {
+					b, err := ioutil.ReadAll(body)
+					require.NoError(t, err)
+					require.Equal(t, "PRODUCT NAME,PRICE\nTest Product,1000\nTest Product2,2538\n", string(b))
+
+					return report, nil
+				})
+			},
+		},
+		{
+			name:         "missing_metadata",
+			chunks:       []*pb.UploadChunk{dataChunk("PRODUCT NAME;PRICE\n")},
+			errCode:      codes.InvalidArgument,
+			errMsg:       "the first message must carry the upload metadata, and only the first",
+			mockBehavior: func(r *mock_grpcHandler.MockProductService) {},
+		},
+		{
+			name: "metadata_twice",
+			chunks: []*pb.UploadChunk{
+				metadataChunk(&pb.UploadMetadata{Name: "products.csv"}),
+				dataChunk("PRODUCT NAME;PRICE\n"),
+				metadataChunk(&pb.UploadMetadata{Name: "products.csv"}),
+			},
+			errCode: codes.InvalidArgument,
+			errMsg:  "the first message must carry the upload metadata, and only the first",
+			mockBehavior: func(r *mock_grpcHandler.MockProductService) {
+				r.EXPECT().Upload(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ string, body io.Reader, _ core.FetchOptions) (core.FetchReport, error) {
+					_, err := ioutil.ReadAll(body)
+
+					return core.FetchReport{}, &core.FeedError{Err: err}
+				})
+			},
+		},
+		{
+			name:         "invalid_format",
+			chunks:       []*pb.UploadChunk{metadataChunk(&pb.UploadMetadata{Name: "products.csv", Format: "yaml"})},
+			errCode:      codes.InvalidArgument,
+			errMsg:       "invalid filter params",
+			mockBehavior: func(r *mock_grpcHandler.MockProductService) {},
+		},
+		{
+			name:    "error_when_reading_feed",
+			chunks:  []*pb.UploadChunk{metadataChunk(&pb.UploadMetadata{Name: "products.csv"}), dataChunk("")},
+			errCode: codes.InvalidArgument,
+			errMsg:  "empty csv file given",
+			mockBehavior: func(r *mock_grpcHandler.MockProductService) {
+				r.EXPECT().Upload(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(core.FetchReport{}, &core.FeedError{Err: errors.New("empty csv file given")})
+			},
+		},
+	}
+
+	client, productService := mockUploadClient(t)
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			s.mockBehavior(productService)
+
+			stream, err := client.Upload(context.Background())
+			require.NoError(t, err)
+
+			for _, chunk := range s.chunks {
+				if err = stream.Send(chunk); err != nil {
+					break
+				}
+			}
+
+			resp, err := stream.CloseAndRecv()
+			if s.errCode != codes.OK {
+				er, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, s.errCode, er.Code())
+				require.Equal(t, s.errMsg, er.Message())
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			res := PBFetchReportToStruct(resp.Report)
+			res.Duration = 0
+			require.Equal(t, s.expResp, res)
+		})
+	}
+}
@@ -6,11 +6,13 @@ package mock_grpcHandler
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 
 	core "github.com/ernur-eskermes/product-store/internal/core"
 	filters "github.com/ernur-eskermes/product-store/pkg/filters"
 	gomock "github.com/golang/mock/gomock"
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // MockProductService is a mock of ProductService interface.
@@ -36,61 +38,168 @@ func (m *MockProductService) EXPECT() *MockProductServiceMockRecorder {
 	return m.recorder
 }
 
+// Fetch mocks base method.
+func (m *MockProductService) Fetch(ctx context.Context, url string, opts core.FetchOptions, progress func(int)) (core.FetchReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fetch", ctx, url, opts, progress)
+	ret0, _ := ret[0].(core.FetchReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Fetch indicates an expected call of Fetch.
+func (mr *MockProductServiceMockRecorder) Fetch(ctx, url, opts, progress interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fetch", reflect.TypeOf((*MockProductService)(nil).Fetch), ctx, url, opts, progress)
+}
+
 // GetAll mocks base method.
-func (m *MockProductService) GetAll(ctx context.Context, f *filters.Filters) ([]core.Product, error) {
+func (m *MockProductService) GetAll(ctx context.Context, filter core.ProductFilter, f *filters.Filters, pageToken string) ([]core.Product, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", ctx, f)
+	ret := m.ctrl.Call(m, "GetAll", ctx, filter, f, pageToken)
 	ret0, _ := ret[0].([]core.Product)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockProductServiceMockRecorder) GetAll(ctx, filter, f, pageToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockProductService)(nil).GetAll), ctx, filter, f, pageToken)
+}
+
+// GetByID mocks base method.
+func (m *MockProductService) GetByID(ctx context.Context, id primitive.ObjectID) (core.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(core.Product)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAll indicates an expected call of GetAll.
-func (mr *MockProductServiceMockRecorder) GetAll(ctx, f interface{}) *gomock.Call {
+// GetByID indicates an expected call of GetByID.
+func (mr *MockProductServiceMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockProductService)(nil).GetAll), ctx, f)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockProductService)(nil).GetByID), ctx, id)
 }
 
-// GetCSVProducts mocks base method.
-func (m *MockProductService) GetCSVProducts(ctx context.Context, url string) ([]core.Product, error) {
+// GetBySKU mocks base method.
+func (m *MockProductService) GetBySKU(ctx context.Context, sku string) (core.Product, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCSVProducts", ctx, url)
-	ret0, _ := ret[0].([]core.Product)
+	ret := m.ctrl.Call(m, "GetBySKU", ctx, sku)
+	ret0, _ := ret[0].(core.Product)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetCSVProducts indicates an expected call of GetCSVProducts.
-func (mr *MockProductServiceMockRecorder) GetCSVProducts(ctx, url interface{}) *gomock.Call {
+// GetBySKU indicates an expected call of GetBySKU.
+func (mr *MockProductServiceMockRecorder) GetBySKU(ctx, sku interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCSVProducts", reflect.TypeOf((*MockProductService)(nil).GetCSVProducts), ctx, url)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBySKU", reflect.TypeOf((*MockProductService)(nil).GetBySKU), ctx, sku)
+}
+
+// GetPriceHistory mocks base method.
+func (m *MockProductService) GetPriceHistory(ctx context.Context, filter core.PriceHistoryFilter, f *filters.Filters) ([]core.PriceChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPriceHistory", ctx, filter, f)
+	ret0, _ := ret[0].([]core.PriceChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPriceHistory indicates an expected call of GetPriceHistory.
+func (mr *MockProductServiceMockRecorder) GetPriceHistory(ctx, filter, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPriceHistory", reflect.TypeOf((*MockProductService)(nil).GetPriceHistory), ctx, filter, f)
+}
+
+// GetPriceHistoryTotalRecords mocks base method.
+func (m *MockProductService) GetPriceHistoryTotalRecords(ctx context.Context, filter core.PriceHistoryFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPriceHistoryTotalRecords", ctx, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPriceHistoryTotalRecords indicates an expected call of GetPriceHistoryTotalRecords.
+func (mr *MockProductServiceMockRecorder) GetPriceHistoryTotalRecords(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPriceHistoryTotalRecords", reflect.TypeOf((*MockProductService)(nil).GetPriceHistoryTotalRecords), ctx, filter)
+}
+
+// GetSearchTotalRecords mocks base method.
+func (m *MockProductService) GetSearchTotalRecords(ctx context.Context, query string, filter core.ProductFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSearchTotalRecords", ctx, query, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSearchTotalRecords indicates an expected call of GetSearchTotalRecords.
+func (mr *MockProductServiceMockRecorder) GetSearchTotalRecords(ctx, query, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSearchTotalRecords", reflect.TypeOf((*MockProductService)(nil).GetSearchTotalRecords), ctx, query, filter)
 }
 
 // GetTotalRecords mocks base method.
-func (m *MockProductService) GetTotalRecords(ctx context.Context) (int64, error) {
+func (m *MockProductService) GetTotalRecords(ctx context.Context, filter core.ProductFilter) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTotalRecords", ctx)
+	ret := m.ctrl.Call(m, "GetTotalRecords", ctx, filter)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetTotalRecords indicates an expected call of GetTotalRecords.
-func (mr *MockProductServiceMockRecorder) GetTotalRecords(ctx interface{}) *gomock.Call {
+func (mr *MockProductServiceMockRecorder) GetTotalRecords(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotalRecords", reflect.TypeOf((*MockProductService)(nil).GetTotalRecords), ctx, filter)
+}
+
+// Search mocks base method.
+func (m *MockProductService) Search(ctx context.Context, query string, filter core.ProductFilter, f *filters.Filters) ([]core.SearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, query, filter, f)
+	ret0, _ := ret[0].([]core.SearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockProductServiceMockRecorder) Search(ctx, query, filter, f interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotalRecords", reflect.TypeOf((*MockProductService)(nil).GetTotalRecords), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockProductService)(nil).Search), ctx, query, filter, f)
 }
 
-// UpdateOrCreate mocks base method.
-func (m *MockProductService) UpdateOrCreate(ctx context.Context, products []core.Product) error {
+// Suggest mocks base method.
+func (m *MockProductService) Suggest(ctx context.Context, prefix string, limit int) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateOrCreate", ctx, products)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "Suggest", ctx, prefix, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Suggest indicates an expected call of Suggest.
+func (mr *MockProductServiceMockRecorder) Suggest(ctx, prefix, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Suggest", reflect.TypeOf((*MockProductService)(nil).Suggest), ctx, prefix, limit)
+}
+
+// Upload mocks base method.
+func (m *MockProductService) Upload(ctx context.Context, name string, r io.Reader, opts core.FetchOptions) (core.FetchReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upload", ctx, name, r, opts)
+	ret0, _ := ret[0].(core.FetchReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// UpdateOrCreate indicates an expected call of UpdateOrCreate.
-func (mr *MockProductServiceMockRecorder) UpdateOrCreate(ctx, products interface{}) *gomock.Call {
+// Upload indicates an expected call of Upload.
+func (mr *MockProductServiceMockRecorder) Upload(ctx, name, r, opts interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrCreate", reflect.TypeOf((*MockProductService)(nil).UpdateOrCreate), ctx, products)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upload", reflect.TypeOf((*MockProductService)(nil).Upload), ctx, name, r, opts)
 }
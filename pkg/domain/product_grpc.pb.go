@@ -8,7 +8,6 @@ package domain
 
 import (
 	context "context"
-	empty "github.com/golang/protobuf/ptypes/empty"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -23,8 +22,21 @@ const _ = grpc.SupportPackageIsVersion7
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ProductServiceClient interface {
-	Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*FetchResponse, error)
+	Upload(ctx context.Context, opts ...grpc.CallOption) (ProductService_UploadClient, error)
 	List(ctx context.Context, opts ...grpc.CallOption) (ProductService_ListClient, error)
+	GetProduct(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*Product, error)
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	Suggest(ctx context.Context, in *SuggestRequest, opts ...grpc.CallOption) (*SuggestResponse, error)
+	GetPriceHistory(ctx context.Context, in *GetPriceHistoryRequest, opts ...grpc.CallOption) (*GetPriceHistoryResponse, error)
+	GetFetchJob(ctx context.Context, in *GetFetchJobRequest, opts ...grpc.CallOption) (*FetchJob, error)
+	ListFetchJobs(ctx context.Context, in *ListFetchJobsRequest, opts ...grpc.CallOption) (*ListFetchJobsResponse, error)
+	CancelFetchJob(ctx context.Context, in *CancelFetchJobRequest, opts ...grpc.CallOption) (*FetchJob, error)
+	CreateFeedSource(ctx context.Context, in *FeedSource, opts ...grpc.CallOption) (*FeedSource, error)
+	GetFeedSource(ctx context.Context, in *GetFeedSourceRequest, opts ...grpc.CallOption) (*FeedSource, error)
+	ListFeedSources(ctx context.Context, in *ListFeedSourcesRequest, opts ...grpc.CallOption) (*ListFeedSourcesResponse, error)
+	UpdateFeedSource(ctx context.Context, in *FeedSource, opts ...grpc.CallOption) (*FeedSource, error)
+	DeleteFeedSource(ctx context.Context, in *DeleteFeedSourceRequest, opts ...grpc.CallOption) (*DeleteFeedSourceResponse, error)
 }
 
 type productServiceClient struct {
@@ -35,8 +47,8 @@ func NewProductServiceClient(cc grpc.ClientConnInterface) ProductServiceClient {
 	return &productServiceClient{cc}
 }
 
-func (c *productServiceClient) Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
-	out := new(empty.Empty)
+func (c *productServiceClient) Fetch(ctx context.Context, in *FetchRequest, opts ...grpc.CallOption) (*FetchResponse, error) {
+	out := new(FetchResponse)
 	err := c.cc.Invoke(ctx, "/product.ProductService/Fetch", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -44,8 +56,42 @@ func (c *productServiceClient) Fetch(ctx context.Context, in *FetchRequest, opts
 	return out, nil
 }
 
+func (c *productServiceClient) Upload(ctx context.Context, opts ...grpc.CallOption) (ProductService_UploadClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ProductService_ServiceDesc.Streams[0], "/product.ProductService/Upload", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &productServiceUploadClient{stream}
+	return x, nil
+}
+
+type ProductService_UploadClient interface {
+	Send(*UploadChunk) error
+	CloseAndRecv() (*FetchResponse, error)
+	grpc.ClientStream
+}
+
+type productServiceUploadClient struct {
+	grpc.ClientStream
+}
+
+func (x *productServiceUploadClient) Send(m *UploadChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *productServiceUploadClient) CloseAndRecv() (*FetchResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(FetchResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *productServiceClient) List(ctx context.Context, opts ...grpc.CallOption) (ProductService_ListClient, error) {
-	stream, err := c.cc.NewStream(ctx, &ProductService_ServiceDesc.Streams[0], "/product.ProductService/List", opts...)
+	stream, err := c.cc.NewStream(ctx, &ProductService_ServiceDesc.Streams[1], "/product.ProductService/List", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -75,12 +121,133 @@ func (x *productServiceListClient) Recv() (*ListResponse, error) {
 	return m, nil
 }
 
+func (c *productServiceClient) GetProduct(ctx context.Context, in *GetProductRequest, opts ...grpc.CallOption) (*Product, error) {
+	out := new(Product)
+	err := c.cc.Invoke(ctx, "/product.ProductService/GetProduct", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, "/product.ProductService/Search", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) Suggest(ctx context.Context, in *SuggestRequest, opts ...grpc.CallOption) (*SuggestResponse, error) {
+	out := new(SuggestResponse)
+	err := c.cc.Invoke(ctx, "/product.ProductService/Suggest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) GetPriceHistory(ctx context.Context, in *GetPriceHistoryRequest, opts ...grpc.CallOption) (*GetPriceHistoryResponse, error) {
+	out := new(GetPriceHistoryResponse)
+	err := c.cc.Invoke(ctx, "/product.ProductService/GetPriceHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) GetFetchJob(ctx context.Context, in *GetFetchJobRequest, opts ...grpc.CallOption) (*FetchJob, error) {
+	out := new(FetchJob)
+	err := c.cc.Invoke(ctx, "/product.ProductService/GetFetchJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) ListFetchJobs(ctx context.Context, in *ListFetchJobsRequest, opts ...grpc.CallOption) (*ListFetchJobsResponse, error) {
+	out := new(ListFetchJobsResponse)
+	err := c.cc.Invoke(ctx, "/product.ProductService/ListFetchJobs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) CancelFetchJob(ctx context.Context, in *CancelFetchJobRequest, opts ...grpc.CallOption) (*FetchJob, error) {
+	out := new(FetchJob)
+	err := c.cc.Invoke(ctx, "/product.ProductService/CancelFetchJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) CreateFeedSource(ctx context.Context, in *FeedSource, opts ...grpc.CallOption) (*FeedSource, error) {
+	out := new(FeedSource)
+	err := c.cc.Invoke(ctx, "/product.ProductService/CreateFeedSource", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) GetFeedSource(ctx context.Context, in *GetFeedSourceRequest, opts ...grpc.CallOption) (*FeedSource, error) {
+	out := new(FeedSource)
+	err := c.cc.Invoke(ctx, "/product.ProductService/GetFeedSource", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) ListFeedSources(ctx context.Context, in *ListFeedSourcesRequest, opts ...grpc.CallOption) (*ListFeedSourcesResponse, error) {
+	out := new(ListFeedSourcesResponse)
+	err := c.cc.Invoke(ctx, "/product.ProductService/ListFeedSources", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) UpdateFeedSource(ctx context.Context, in *FeedSource, opts ...grpc.CallOption) (*FeedSource, error) {
+	out := new(FeedSource)
+	err := c.cc.Invoke(ctx, "/product.ProductService/UpdateFeedSource", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) DeleteFeedSource(ctx context.Context, in *DeleteFeedSourceRequest, opts ...grpc.CallOption) (*DeleteFeedSourceResponse, error) {
+	out := new(DeleteFeedSourceResponse)
+	err := c.cc.Invoke(ctx, "/product.ProductService/DeleteFeedSource", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ProductServiceServer is the server API for ProductService service.
 // All implementations must embed UnimplementedProductServiceServer
 // for forward compatibility
 type ProductServiceServer interface {
-	Fetch(context.Context, *FetchRequest) (*empty.Empty, error)
+	Fetch(context.Context, *FetchRequest) (*FetchResponse, error)
+	Upload(ProductService_UploadServer) error
 	List(ProductService_ListServer) error
+	GetProduct(context.Context, *GetProductRequest) (*Product, error)
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	Suggest(context.Context, *SuggestRequest) (*SuggestResponse, error)
+	GetPriceHistory(context.Context, *GetPriceHistoryRequest) (*GetPriceHistoryResponse, error)
+	GetFetchJob(context.Context, *GetFetchJobRequest) (*FetchJob, error)
+	ListFetchJobs(context.Context, *ListFetchJobsRequest) (*ListFetchJobsResponse, error)
+	CancelFetchJob(context.Context, *CancelFetchJobRequest) (*FetchJob, error)
+	CreateFeedSource(context.Context, *FeedSource) (*FeedSource, error)
+	GetFeedSource(context.Context, *GetFeedSourceRequest) (*FeedSource, error)
+	ListFeedSources(context.Context, *ListFeedSourcesRequest) (*ListFeedSourcesResponse, error)
+	UpdateFeedSource(context.Context, *FeedSource) (*FeedSource, error)
+	DeleteFeedSource(context.Context, *DeleteFeedSourceRequest) (*DeleteFeedSourceResponse, error)
 	mustEmbedUnimplementedProductServiceServer()
 }
 
@@ -88,12 +255,51 @@ type ProductServiceServer interface {
 type UnimplementedProductServiceServer struct {
 }
 
-func (UnimplementedProductServiceServer) Fetch(context.Context, *FetchRequest) (*empty.Empty, error) {
+func (UnimplementedProductServiceServer) Fetch(context.Context, *FetchRequest) (*FetchResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Fetch not implemented")
 }
+func (UnimplementedProductServiceServer) Upload(ProductService_UploadServer) error {
+	return status.Errorf(codes.Unimplemented, "method Upload not implemented")
+}
 func (UnimplementedProductServiceServer) List(ProductService_ListServer) error {
 	return status.Errorf(codes.Unimplemented, "method List not implemented")
 }
+func (UnimplementedProductServiceServer) GetProduct(context.Context, *GetProductRequest) (*Product, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProduct not implemented")
+}
+func (UnimplementedProductServiceServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedProductServiceServer) Suggest(context.Context, *SuggestRequest) (*SuggestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Suggest not implemented")
+}
+func (UnimplementedProductServiceServer) GetPriceHistory(context.Context, *GetPriceHistoryRequest) (*GetPriceHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPriceHistory not implemented")
+}
+func (UnimplementedProductServiceServer) GetFetchJob(context.Context, *GetFetchJobRequest) (*FetchJob, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFetchJob not implemented")
+}
+func (UnimplementedProductServiceServer) ListFetchJobs(context.Context, *ListFetchJobsRequest) (*ListFetchJobsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFetchJobs not implemented")
+}
+func (UnimplementedProductServiceServer) CancelFetchJob(context.Context, *CancelFetchJobRequest) (*FetchJob, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelFetchJob not implemented")
+}
+func (UnimplementedProductServiceServer) CreateFeedSource(context.Context, *FeedSource) (*FeedSource, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateFeedSource not implemented")
+}
+func (UnimplementedProductServiceServer) GetFeedSource(context.Context, *GetFeedSourceRequest) (*FeedSource, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFeedSource not implemented")
+}
+func (UnimplementedProductServiceServer) ListFeedSources(context.Context, *ListFeedSourcesRequest) (*ListFeedSourcesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFeedSources not implemented")
+}
+func (UnimplementedProductServiceServer) UpdateFeedSource(context.Context, *FeedSource) (*FeedSource, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateFeedSource not implemented")
+}
+func (UnimplementedProductServiceServer) DeleteFeedSource(context.Context, *DeleteFeedSourceRequest) (*DeleteFeedSourceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteFeedSource not implemented")
+}
 func (UnimplementedProductServiceServer) mustEmbedUnimplementedProductServiceServer() {}
 
 // UnsafeProductServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -125,6 +331,32 @@ func _ProductService_Fetch_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProductService_Upload_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ProductServiceServer).Upload(&productServiceUploadServer{stream})
+}
+
+type ProductService_UploadServer interface {
+	SendAndClose(*FetchResponse) error
+	Recv() (*UploadChunk, error)
+	grpc.ServerStream
+}
+
+type productServiceUploadServer struct {
+	grpc.ServerStream
+}
+
+func (x *productServiceUploadServer) SendAndClose(m *FetchResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *productServiceUploadServer) Recv() (*UploadChunk, error) {
+	m := new(UploadChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _ProductService_List_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(ProductServiceServer).List(&productServiceListServer{stream})
 }
@@ -151,6 +383,222 @@ func (x *productServiceListServer) Recv() (*Filters, error) {
 	return m, nil
 }
 
+func _ProductService_GetProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).GetProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/product.ProductService/GetProduct",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).GetProduct(ctx, req.(*GetProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/product.ProductService/Search",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_Suggest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SuggestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).Suggest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/product.ProductService/Suggest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).Suggest(ctx, req.(*SuggestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_GetPriceHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPriceHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).GetPriceHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/product.ProductService/GetPriceHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).GetPriceHistory(ctx, req.(*GetPriceHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_GetFetchJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFetchJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).GetFetchJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/product.ProductService/GetFetchJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).GetFetchJob(ctx, req.(*GetFetchJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_ListFetchJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFetchJobsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).ListFetchJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/product.ProductService/ListFetchJobs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).ListFetchJobs(ctx, req.(*ListFetchJobsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_CancelFetchJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelFetchJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).CancelFetchJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/product.ProductService/CancelFetchJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).CancelFetchJob(ctx, req.(*CancelFetchJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_CreateFeedSource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FeedSource)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).CreateFeedSource(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/product.ProductService/CreateFeedSource",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).CreateFeedSource(ctx, req.(*FeedSource))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_GetFeedSource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFeedSourceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).GetFeedSource(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/product.ProductService/GetFeedSource",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).GetFeedSource(ctx, req.(*GetFeedSourceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_ListFeedSources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFeedSourcesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).ListFeedSources(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/product.ProductService/ListFeedSources",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).ListFeedSources(ctx, req.(*ListFeedSourcesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_UpdateFeedSource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FeedSource)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).UpdateFeedSource(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/product.ProductService/UpdateFeedSource",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).UpdateFeedSource(ctx, req.(*FeedSource))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_DeleteFeedSource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteFeedSourceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).DeleteFeedSource(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/product.ProductService/DeleteFeedSource",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).DeleteFeedSource(ctx, req.(*DeleteFeedSourceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ProductService_ServiceDesc is the grpc.ServiceDesc for ProductService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -162,8 +610,61 @@ var ProductService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Fetch",
 			Handler:    _ProductService_Fetch_Handler,
 		},
+		{
+			MethodName: "GetProduct",
+			Handler:    _ProductService_GetProduct_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _ProductService_Search_Handler,
+		},
+		{
+			MethodName: "Suggest",
+			Handler:    _ProductService_Suggest_Handler,
+		},
+		{
+			MethodName: "GetPriceHistory",
+			Handler:    _ProductService_GetPriceHistory_Handler,
+		},
+		{
+			MethodName: "GetFetchJob",
+			Handler:    _ProductService_GetFetchJob_Handler,
+		},
+		{
+			MethodName: "ListFetchJobs",
+			Handler:    _ProductService_ListFetchJobs_Handler,
+		},
+		{
+			MethodName: "CancelFetchJob",
+			Handler:    _ProductService_CancelFetchJob_Handler,
+		},
+		{
+			MethodName: "CreateFeedSource",
+			Handler:    _ProductService_CreateFeedSource_Handler,
+		},
+		{
+			MethodName: "GetFeedSource",
+			Handler:    _ProductService_GetFeedSource_Handler,
+		},
+		{
+			MethodName: "ListFeedSources",
+			Handler:    _ProductService_ListFeedSources_Handler,
+		},
+		{
+			MethodName: "UpdateFeedSource",
+			Handler:    _ProductService_UpdateFeedSource_Handler,
+		},
+		{
+			MethodName: "DeleteFeedSource",
+			Handler:    _ProductService_DeleteFeedSource_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Upload",
+			Handler:       _ProductService_Upload_Handler,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "List",
 			Handler:       _ProductService_List_Handler,
@@ -2,6 +2,9 @@ package filters
 
 import (
 	"strings"
+	"unicode/utf8"
+
+	"github.com/ernur-eskermes/product-store/pkg/iso4217"
 )
 
 const (
@@ -9,11 +12,27 @@ const (
 	DESC     = -1
 )
 
+// maxTextFilterLength is the longest text a name filter may hold, in characters.
+const maxTextFilterLength = 255
+
 type Filters struct {
 	Page         int64
 	PageSize     int64
 	Sort         string
 	SortSafeList []string
+
+	// MinPrice and MaxPrice bound the prices listed, both included. Either may be nil.
+	MinPrice *Price
+	MaxPrice *Price
+	// NamePrefix and NameContains match names by prefix and by substring.
+	NamePrefix   string
+	NameContains string
+}
+
+// Price is a price bound: Amount minor units of Currency, an ISO 4217 code.
+type Price struct {
+	Amount   int64
+	Currency string
 }
 
 func New(page int64, pageSize int64, sort, defaultSort string, sortSafeList []string) *Filters {
@@ -62,10 +81,25 @@ func ValidateFilters(f *Filters) error {
 		messages = append(messages, ErrorResponse{"page_size", "must be a maximum of 100"})
 	}
 
-	if f.Sort != "" && f.SortColumn() == "" {
+	if f.Sort != "" && f.SortKeys() == nil {
 		messages = append(messages, ErrorResponse{"sort", "invalid sort value"})
 	}
 
+	messages = append(messages, validatePrice("min_price", f.MinPrice)...)
+	messages = append(messages, validatePrice("max_price", f.MaxPrice)...)
+
+	if f.MinPrice != nil && f.MaxPrice != nil {
+		switch {
+		case f.MinPrice.Currency != f.MaxPrice.Currency:
+			messages = append(messages, ErrorResponse{"max_price", "must be in the currency of min_price"})
+		case f.MaxPrice.Amount < f.MinPrice.Amount:
+			messages = append(messages, ErrorResponse{"max_price", "must not be less than min_price"})
+		}
+	}
+
+	messages = append(messages, validateText("name_prefix", f.NamePrefix)...)
+	messages = append(messages, validateText("name_contains", f.NameContains)...)
+
 	if len(messages) != 0 {
 		return messages
 	}
@@ -73,17 +107,90 @@ func ValidateFilters(f *Filters) error {
 	return nil
 }
 
-func (f Filters) SortColumn() string {
-	for _, safeValue := range f.SortSafeList {
-		if f.Sort == safeValue {
-			return strings.TrimPrefix(f.Sort, "-")
+func validatePrice(field string, p *Price) ValidationErrors {
+	if p == nil {
+		return nil
+	}
+
+	var messages ValidationErrors
+
+	if p.Amount < 0 {
+		messages = append(messages, ErrorResponse{field + ".amount", "must not be negative"})
+	}
+
+	if !iso4217.Valid(p.Currency) {
+		messages = append(messages, ErrorResponse{field + ".currency", "must be a three-letter currency code"})
+	}
+
+	return messages
+}
+
+func validateText(field, value string) ValidationErrors {
+	switch {
+	case !utf8.ValidString(value):
+		return ValidationErrors{{field, "must be valid UTF-8"}}
+	case utf8.RuneCountInString(value) > maxTextFilterLength:
+		return ValidationErrors{{field, "must be a maximum of 255 characters"}}
+	default:
+		return nil
+	}
+}
+
+// SortKey is one key of a sort: a column, and the direction it is sorted in.
+type SortKey struct {
+	Column    string
+	Direction int
+}
+
+// SortKeys returns the keys of Sort, a comma-separated list of values of SortSafeList
+// such as "-price,name", most significant first. It returns nil if a key is not in
+// SortSafeList, or a column is sorted on twice.
+func (f Filters) SortKeys() []SortKey {
+	var keys []SortKey
+
+	for _, value := range strings.Split(f.Sort, ",") {
+		value = strings.TrimSpace(value)
+		if !f.safeSort(value) {
+			return nil
+		}
+
+		key := SortKey{Column: strings.TrimPrefix(value, "-"), Direction: ASC}
+		if strings.HasPrefix(value, "-") {
+			key.Direction = DESC
+		}
+
+		for _, k := range keys {
+			if k.Column == key.Column {
+				return nil
+			}
 		}
+
+		keys = append(keys, key)
 	}
 
-	return ""
+	return keys
 }
 
+// SortColumn returns the column of the first key of Sort, or "" if Sort is invalid.
+//
+// Deprecated: Sort may hold several keys; use SortKeys.
+func (f Filters) SortColumn() string {
+	keys := f.SortKeys()
+	if keys == nil {
+		return ""
+	}
+
+	return keys[0].Column
+}
+
+// SortDirection returns the direction of the first key of Sort.
+//
+// Deprecated: Sort may hold several keys; use SortKeys.
 func (f Filters) SortDirection() int {
+	if keys := f.SortKeys(); keys != nil {
+		return keys[0].Direction
+	}
+
 	if strings.HasPrefix(f.Sort, "-") {
 		return DESC
 	}
@@ -91,6 +198,16 @@ func (f Filters) SortDirection() int {
 	return ASC
 }
 
+func (f Filters) safeSort(value string) bool {
+	for _, safeValue := range f.SortSafeList {
+		if value == safeValue {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (f Filters) Limit() int64 {
 	return f.PageSize
 }
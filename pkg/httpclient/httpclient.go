@@ -0,0 +1,254 @@
+package httpclient
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Policy restricts where a client may connect and how much it may download.
+//
+// A host entry matches that host exactly, or, when it starts with a dot, any subdomain
+// of it: ".example.com" matches "feeds.example.com". An empty AllowedHosts allows any
+// host that is not denied. Loopback, private, link-local and other non-public
+// addresses are refused once the host is resolved, unless AllowPrivateNetworks is set.
+// A zero MaxBodySize is not enforced, while a zero MaxRedirects follows no redirects.
+type Policy struct {
+	AllowedSchemes       []string
+	AllowedHosts         []string
+	DeniedHosts          []string
+	AllowPrivateNetworks bool
+	MaxRedirects         int
+	MaxBodySize          int64
+}
+
+// PolicyError reports a request refused by the Policy of a client.
+type PolicyError struct {
+	Reason string
+}
+
+func (e *PolicyError) Error() string {
+	return e.Reason
+}
+
+const dialTimeout = 30 * time.Second
+
+var nonPublicNetworks = mustParseCIDRs(
+	"0.0.0.0/8",     // "this" network
+	"100.64.0.0/10", // carrier-grade NAT
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // benchmarking
+	"64:ff9b::/96",  // NAT64, may reach IPv4 private ranges
+)
+
+// New returns an http.Client that enforces p. Requests go straight to the target, not
+// through a proxy, so that the resolved address checked is the one connected to.
+func New(p Policy) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: dialTimeout,
+		Control: p.checkDial,
+	}
+
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	return &http.Client{
+		Transport:     &policyTransport{policy: p, next: transport},
+		CheckRedirect: p.checkRedirect,
+	}
+}
+
+// CheckURL tells whether p allows requests to scheme and host. It does not resolve the
+// host; addresses are checked when connecting.
+func (p Policy) CheckURL(scheme, host string) error {
+	scheme = strings.ToLower(scheme)
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	if len(p.AllowedSchemes) != 0 && !contains(p.AllowedSchemes, scheme) {
+		return &PolicyError{Reason: fmt.Sprintf("scheme %q is not allowed", scheme)}
+	}
+
+	if matchHost(p.DeniedHosts, host) {
+		return &PolicyError{Reason: fmt.Sprintf("host %q is not allowed", host)}
+	}
+
+	if len(p.AllowedHosts) != 0 && !matchHost(p.AllowedHosts, host) {
+		return &PolicyError{Reason: fmt.Sprintf("host %q is not allowed", host)}
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return p.checkIP(ip)
+	}
+
+	return nil
+}
+
+func (p Policy) checkIP(ip net.IP) error {
+	if p.AllowPrivateNetworks || publicIP(ip) {
+		return nil
+	}
+
+	return &PolicyError{Reason: fmt.Sprintf("address %s is not public", ip)}
+}
+
+// checkDial runs before every connection, after the host has been resolved, so a name
+// resolving to a private address is refused as well.
+func (p Policy) checkDial(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return &PolicyError{Reason: fmt.Sprintf("address %s is not an IP address", host)}
+	}
+
+	return p.checkIP(ip)
+}
+
+// checkRedirect limits the number of redirects and keeps the headers of the first
+// request from following a redirect to another host or scheme, so that they are not
+// sent elsewhere or in cleartext. The client itself only drops Authorization and
+// cookies, not credentials such as API keys sent in other headers.
+func (p Policy) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > p.MaxRedirects {
+		return &PolicyError{Reason: fmt.Sprintf("stopped after %d redirects", p.MaxRedirects)}
+	}
+
+	if !strings.EqualFold(req.URL.Host, via[0].URL.Host) || !strings.EqualFold(req.URL.Scheme, via[0].URL.Scheme) {
+		for name := range via[0].Header {
+			req.Header.Del(name)
+		}
+	}
+
+	return nil
+}
+
+// policyTransport checks every request, including those following redirects, and
+// limits the size of every response body.
+type policyTransport struct {
+	policy Policy
+	next   http.RoundTripper
+}
+
+func (t *policyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.policy.CheckURL(req.URL.Scheme, req.URL.Hostname()); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.policy.MaxBodySize > 0 {
+		resp.Body = &limitedBody{
+			ReadCloser: resp.Body,
+			limit:      t.policy.MaxBodySize,
+			left:       t.policy.MaxBodySize,
+			tooLarge:   resp.ContentLength > t.policy.MaxBodySize,
+		}
+	}
+
+	return resp, nil
+}
+
+// limitedBody fails a read that goes past the size limit, instead of cutting the body
+// short as io.LimitReader would. A body declared larger than the limit fails on the
+// first read.
+type limitedBody struct {
+	io.ReadCloser
+	limit    int64
+	left     int64
+	tooLarge bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.tooLarge {
+		return 0, errBodyTooLarge(b.limit)
+	}
+
+	// One byte more than is left tells a body that ends at the limit from one past it.
+	if int64(len(p)) > b.left+1 {
+		p = p[:b.left+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.left {
+		n = int(b.left)
+		b.left = 0
+
+		return n, errBodyTooLarge(b.limit)
+	}
+
+	b.left -= int64(n)
+
+	return n, err
+}
+
+func errBodyTooLarge(limit int64) error {
+	return &PolicyError{Reason: fmt.Sprintf("response body exceeds the size limit of %d bytes", limit)}
+}
+
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+
+	for _, n := range nonPublicNetworks {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchHost(patterns []string, host string) bool {
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+
+		if host == p || (strings.HasPrefix(p, ".") && (strings.HasSuffix(host, p) || host == p[1:])) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if strings.EqualFold(strings.TrimSpace(s), v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	res := make([]*net.IPNet, 0, len(cidrs))
+
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+
+		res = append(res, n)
+	}
+
+	return res
+}
@@ -0,0 +1,192 @@
+package httpclient_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ernur-eskermes/product-store/pkg/httpclient"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "/redirect", http.StatusFound)
+		case "/chunked":
+			w.Write([]byte(strings.Repeat("a", 8)))
+			w.(http.Flusher).Flush()
+			w.Write([]byte(strings.Repeat("a", 8)))
+		default:
+			w.Write([]byte(strings.Repeat("a", 16)))
+		}
+	}))
+	defer srv.Close()
+
+	localhost := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+
+	public := httpclient.Policy{AllowedSchemes: []string{"http", "https"}}
+	private := httpclient.Policy{AllowedSchemes: []string{"http", "https"}, AllowPrivateNetworks: true, MaxRedirects: 2, MaxBodySize: 16}
+
+	cases := []struct {
+		name   string
+		policy httpclient.Policy
+		url    string
+		expErr string
+	}{
+		{
+			name:   "loopback_address",
+			policy: public,
+			url:    srv.URL,
+			expErr: "address 127.0.0.1 is not public",
+		},
+		{
+			name:   "name_resolving_to_loopback",
+			policy: public,
+			url:    localhost,
+			expErr: "is not public",
+		},
+		{
+			name:   "metadata_address",
+			policy: public,
+			url:    "http://169.254.169.254/latest/meta-data",
+			expErr: "address 169.254.169.254 is not public",
+		},
+		{
+			name:   "scheme_not_allowed",
+			policy: public,
+			url:    "ftp://example.com/feed.csv",
+			expErr: `scheme "ftp" is not allowed`,
+		},
+		{
+			name:   "denied_host",
+			policy: httpclient.Policy{DeniedHosts: []string{".internal"}},
+			url:    "http://metadata.google.internal/",
+			expErr: `host "metadata.google.internal" is not allowed`,
+		},
+		{
+			name:   "host_not_allowed",
+			policy: httpclient.Policy{AllowedHosts: []string{".example.com"}},
+			url:    "http://example.org/",
+			expErr: `host "example.org" is not allowed`,
+		},
+		{
+			name:   "private_networks_allowed",
+			policy: private,
+			url:    srv.URL,
+		},
+		{
+			name:   "too_many_redirects",
+			policy: private,
+			url:    srv.URL + "/redirect",
+			expErr: "stopped after 2 redirects",
+		},
+		{
+			name:   "body_too_large",
+			policy: httpclient.Policy{AllowPrivateNetworks: true, MaxBodySize: 10},
+			url:    srv.URL,
+			expErr: "response body exceeds the size limit of 10 bytes",
+		},
+		{
+			name:   "chunked_body_too_large",
+			policy: httpclient.Policy{AllowPrivateNetworks: true, MaxBodySize: 10},
+			url:    srv.URL + "/chunked",
+			expErr: "response body exceeds the size limit of 10 bytes",
+		},
+	}
+
+	for _, s := range cases {
+		t.Run(s.name, func(t *testing.T) {
+			err := get(httpclient.New(s.policy), s.url)
+			if s.expErr == "" {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorAs(t, err, new(*httpclient.PolicyError))
+			require.Contains(t, err.Error(), s.expErr)
+		})
+	}
+}
+
+func get(client *http.Client, url string) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.ReadAll(resp.Body)
+
+	return err
+}
+
+func TestClient_RedirectHeaders(t *testing.T) {
+	headers := make(chan http.Header, 2)
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header
+	}))
+	defer target.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/same-host":
+			headers <- r.Header
+		case "/other-host":
+			// localhost is another host than 127.0.0.1 to the client.
+			http.Redirect(w, r, strings.Replace(target.URL, "127.0.0.1", "localhost", 1), http.StatusFound)
+		default:
+			http.Redirect(w, r, "/same-host", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+
+	client := httpclient.New(httpclient.Policy{AllowPrivateNetworks: true, MaxRedirects: 2})
+
+	for _, s := range []struct {
+		path   string
+		expKey string
+	}{
+		{path: "/", expKey: "secret"},
+		{path: "/other-host", expKey: ""},
+	} {
+		req, err := http.NewRequest("GET", srv.URL+s.path, nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Api-Key", "secret")
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, s.expKey, (<-headers).Get("X-Api-Key"))
+	}
+}
+
+func TestClient_RedirectSchemeHeaders(t *testing.T) {
+	client := httpclient.New(httpclient.Policy{MaxRedirects: 2})
+
+	for _, s := range []struct {
+		url    string
+		expKey string
+	}{
+		{url: "https://feeds.example.com/feed.csv", expKey: "secret"},
+		{url: "http://feeds.example.com/feed.csv", expKey: ""},
+	} {
+		first, err := http.NewRequest("GET", "https://feeds.example.com/", nil)
+		require.NoError(t, err)
+		first.Header.Set("X-Api-Key", "secret")
+
+		// The client copies the headers of the first request before checking a redirect.
+		req, err := http.NewRequest("GET", s.url, nil)
+		require.NoError(t, err)
+		req.Header = first.Header.Clone()
+
+		require.NoError(t, client.CheckRedirect(req, []*http.Request{first}))
+		require.Equal(t, s.expKey, req.Header.Get("X-Api-Key"))
+	}
+}